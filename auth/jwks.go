@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/windbnb/accomodation-service/clock"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// ErrKeysUnavailable is returned when the signing keys of the user service
+// could not be fetched, so a token can neither be accepted nor rejected.
+var ErrKeysUnavailable = errors.New("signing keys are unavailable")
+
+var defaultHttpClient = &http.Client{Timeout: 10 * time.Second, Transport: &tracer.Transport{}}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// KeySet fetches the public keys of a JWKS endpoint and caches them for
+// RefreshInterval. A token signed with a key it does not know yet triggers an
+// early refresh, at most once per MinRefreshInterval, so rotated keys are
+// picked up without hammering the endpoint with forged key ids. After a failed
+// refresh the endpoint is likewise left alone for MinRefreshInterval.
+type KeySet struct {
+	Url                string
+	RefreshInterval    time.Duration
+	MinRefreshInterval time.Duration
+	HttpClient         *http.Client
+	Clock              clock.Func
+
+	mutex      sync.Mutex
+	keys       map[string]crypto.PublicKey
+	fetchedAt  time.Time
+	failedAt   time.Time
+	failure    error
+	refreshing chan struct{}
+}
+
+func NewKeySet(url string, refreshInterval time.Duration) *KeySet {
+	return &KeySet{Url: url, RefreshInterval: refreshInterval, MinRefreshInterval: 30 * time.Second}
+}
+
+// Key returns the public key with the given key id. Only one caller fetches
+// the keys at a time and never while holding the mutex. The others meanwhile
+// use the cached keys, or wait for the fetch when they have no key to use.
+func (keySet *KeySet) Key(keyId string, ctx context.Context) (crypto.PublicKey, error) {
+	var key crypto.PublicKey
+	var found bool
+	for {
+		keySet.mutex.Lock()
+		now := keySet.Clock.Now()
+		sinceFetch := now.Sub(keySet.fetchedAt)
+		key, found = keySet.keys[keyId]
+		stale := keySet.fetchedAt.IsZero() || sinceFetch >= keySet.RefreshInterval
+		backingOff := !keySet.failedAt.IsZero() && now.Sub(keySet.failedAt) < keySet.MinRefreshInterval
+		if (stale || (!found && sinceFetch >= keySet.MinRefreshInterval)) && keySet.refreshing == nil && !backingOff {
+			keySet.refreshing = make(chan struct{})
+			keySet.mutex.Unlock()
+			keys, err := keySet.refresh(ctx)
+			if keys == nil {
+				return nil, fmt.Errorf("%w: %s", ErrKeysUnavailable, err)
+			}
+			key, found = keys[keyId]
+			break
+		}
+
+		keys, failure, refreshing := keySet.keys, keySet.failure, keySet.refreshing
+		keySet.mutex.Unlock()
+		if refreshing == nil || (keys != nil && found) {
+			if keys == nil {
+				return nil, fmt.Errorf("%w: %s", ErrKeysUnavailable, failure)
+			}
+			break
+		}
+		select {
+		case <-refreshing:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s", ErrKeysUnavailable, ctx.Err())
+		}
+	}
+
+	if !found {
+		return nil, errors.New("token is signed with unknown key " + keyId)
+	}
+	return key, nil
+}
+
+// refresh fetches the keys for a caller that has set refreshing, and falls
+// back to the cached keys when that fails.
+func (keySet *KeySet) refresh(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	keys, err := keySet.fetch(ctx)
+
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	close(keySet.refreshing)
+	keySet.refreshing = nil
+
+	if err == nil {
+		keySet.keys = keys
+		keySet.fetchedAt = keySet.Clock.Now()
+		keySet.failedAt = time.Time{}
+		return keys, nil
+	}
+	// A caller giving up is no reason to stop asking the endpoint.
+	if ctx.Err() == nil {
+		keySet.failedAt = keySet.Clock.Now()
+		keySet.failure = err
+	}
+	if keySet.keys != nil {
+		log.Printf("refreshing signing keys failed, using keys from %s: %s", keySet.fetchedAt.Format(time.RFC3339), err)
+	}
+	return keySet.keys, err
+}
+
+func (keySet *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, keySet.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := keySet.httpClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint responded with status %d", response.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, webKey := range document.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+		key, err := webKey.publicKey()
+		if err != nil {
+			log.Printf("skipping signing key %s: %s", webKey.KeyId, err)
+			continue
+		}
+		keys[webKey.KeyId] = key
+	}
+	return keys, nil
+}
+
+func (webKey jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch webKey.KeyType {
+	case "RSA":
+		modulus, err := decodeBigInt(webKey.N)
+		if err != nil {
+			return nil, err
+		}
+		exponent, err := decodeBigInt(webKey.E)
+		if err != nil {
+			return nil, err
+		}
+		if !exponent.IsInt64() {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch webKey.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, errors.New("unsupported curve " + webKey.Curve)
+		}
+		x, err := decodeBigInt(webKey.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(webKey.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.New("unsupported key type " + webKey.KeyType)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func (keySet *KeySet) httpClient() *http.Client {
+	if keySet.HttpClient != nil {
+		return keySet.HttpClient
+	}
+	return defaultHttpClient
+}
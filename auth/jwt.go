@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+// JWTVerifier authenticates users from JWTs signed by the user service,
+// checking the signature against the keys of its JWKS endpoint instead of
+// asking the user service on every request.
+type JWTVerifier struct {
+	Keys *KeySet
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+}
+
+type userClaims struct {
+	jwt.RegisteredClaims
+	Id       json.Number    `json:"id"`
+	Email    string         `json:"email"`
+	Username string         `json:"username"`
+	Role     model.UserRole `json:"role"`
+}
+
+// Authenticate verifies a token, with or without the "Bearer " prefix, and
+// returns the user from its claims. The user id is taken from the id claim or
+// else from sub.
+func (verifier *JWTVerifier) Authenticate(token string, ctx context.Context) (model.UserResponseDTO, error) {
+	token = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "Bearer "))
+
+	var claims userClaims
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}), jwt.WithJSONNumber())
+	_, err := parser.ParseWithClaims(token, &claims, func(parsedToken *jwt.Token) (interface{}, error) {
+		keyId, _ := parsedToken.Header["kid"].(string)
+		return verifier.Keys.Key(keyId, ctx)
+	})
+	if err != nil {
+		if errors.Is(err, ErrKeysUnavailable) {
+			return model.UserResponseDTO{}, ErrKeysUnavailable
+		}
+		return model.UserResponseDTO{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return model.UserResponseDTO{}, errors.New("invalid token: token has no expiry")
+	}
+	if verifier.Issuer != "" && !claims.VerifyIssuer(verifier.Issuer, true) {
+		return model.UserResponseDTO{}, errors.New("invalid token: unexpected issuer")
+	}
+	if verifier.Audience != "" && !claims.VerifyAudience(verifier.Audience, true) {
+		return model.UserResponseDTO{}, errors.New("invalid token: unexpected audience")
+	}
+
+	userId := claims.Id.String()
+	if userId == "" {
+		userId = claims.Subject
+	}
+	id, err := strconv.ParseUint(userId, 10, 32)
+	if err != nil {
+		return model.UserResponseDTO{}, errors.New("invalid token: user id is missing")
+	}
+
+	return model.UserResponseDTO{Id: uint(id), Email: claims.Email, Username: claims.Username, Role: claims.Role}, nil
+}
+
+// WithFallback authenticates with local and asks remote only when local can
+// not reach a decision because the signing keys are unavailable. Tokens that
+// fail verification are rejected without a remote call.
+func WithFallback(local Authenticator, remote Authenticator) Authenticator {
+	return func(token string, ctx context.Context) (model.UserResponseDTO, error) {
+		user, err := local(token, ctx)
+		if errors.Is(err, ErrKeysUnavailable) {
+			return remote(token, ctx)
+		}
+		return user, err
+	}
+}
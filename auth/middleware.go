@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// Authenticator resolves the user a token from the Authorization header
+// belongs to. The context is the one of the request being authenticated.
+type Authenticator func(token string, ctx context.Context) (model.UserResponseDTO, error)
+
+type userContextKey struct{}
+
+// WithUser returns a context carrying the authenticated user.
+func WithUser(ctx context.Context, user model.UserResponseDTO) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFrom returns the user the auth middleware authenticated for the request.
+func UserFrom(ctx context.Context) (model.UserResponseDTO, bool) {
+	user, found := ctx.Value(userContextKey{}).(model.UserResponseDTO)
+	return user, found
+}
+
+// Require wraps a handler so that it only runs for an authenticated user with
+// one of the given roles. Without roles any authenticated user is let through.
+// The user is resolved once and put into the request context.
+func Require(authenticate Authenticator, roles ...model.UserRole) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get("Authorization")
+			if token == "" {
+				writeUnauthorized(w, errors.New("missing authorization token"))
+				return
+			}
+
+			// Authentication may call the user service, so it is traced as
+			// part of the trace the caller started.
+			span := tracer.StartSpanFromRequest("authenticateMiddleware", tracer.Global(), r)
+			user, err := authenticate(token, tracer.ContextWithSpan(r.Context(), span))
+			if err != nil {
+				tracer.LogError(span, err)
+			}
+			span.Finish()
+			if err != nil {
+				writeUnauthorized(w, err)
+				return
+			}
+			if !hasRole(user, roles) {
+				writeUnauthorized(w, roleError(roles))
+				return
+			}
+
+			next(w, r.WithContext(WithUser(r.Context(), user)))
+		}
+	}
+}
+
+// RequireService wraps a handler so that it only runs for other windbnb
+// services presenting a valid token in the given header.
+func RequireService(header string, authorize func(token string) error) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if err := authorize(r.Header.Get(header)); err != nil {
+				writeUnauthorized(w, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+func hasRole(user model.UserResponseDTO, roles []model.UserRole) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func roleError(roles []model.UserRole) error {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, strings.ToLower(string(role)))
+	}
+	return errors.New("user is not a " + strings.Join(names, " or "))
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+}
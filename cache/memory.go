@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/windbnb/accomodation-service/clock"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps values in process. Once it holds Capacity values, the
+// least recently used one is dropped to make room for a new one.
+type MemoryStore struct {
+	Capacity int
+	Clock    clock.Func
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{Capacity: capacity}
+}
+
+func (store *MemoryStore) Get(key string, ctx context.Context) ([]byte, bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	element, found := store.entries[key]
+	if !found {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !store.Clock.Now().Before(entry.expiresAt) {
+		store.remove(element)
+		return nil, false, nil
+	}
+	store.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (store *MemoryStore) Set(key string, value []byte, ttl time.Duration, ctx context.Context) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.entries == nil {
+		store.entries = map[string]*list.Element{}
+		store.order = list.New()
+	}
+
+	expiresAt := store.Clock.Now().Add(ttl)
+	if element, found := store.entries[key]; found {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		store.order.MoveToFront(element)
+		return nil
+	}
+
+	store.entries[key] = store.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for store.Capacity > 0 && store.order.Len() > store.Capacity {
+		store.remove(store.order.Back())
+	}
+	return nil
+}
+
+func (store *MemoryStore) Delete(key string, ctx context.Context) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if element, found := store.entries[key]; found {
+		store.remove(element)
+	}
+	return nil
+}
+
+// Len returns the number of values held, including expired ones not yet
+// dropped.
+func (store *MemoryStore) Len() int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.order == nil {
+		return 0
+	}
+	return store.order.Len()
+}
+
+func (store *MemoryStore) remove(element *list.Element) {
+	store.order.Remove(element)
+	delete(store.entries, element.Value.(*memoryEntry).key)
+}
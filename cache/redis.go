@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisStore keeps values in Redis so that every instance of the service
+// shares them. Keys are prefixed with Prefix.
+type RedisStore struct {
+	Client *redis.Client
+	Prefix string
+}
+
+func NewRedisStore(address string, prefix string) *RedisStore {
+	return &RedisStore{Client: redis.NewClient(&redis.Options{Addr: address}), Prefix: prefix}
+}
+
+func (store *RedisStore) Get(key string, ctx context.Context) ([]byte, bool, error) {
+	value, err := store.Client.WithContext(ctx).Get(store.Prefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (store *RedisStore) Set(key string, value []byte, ttl time.Duration, ctx context.Context) error {
+	return store.Client.WithContext(ctx).Set(store.Prefix+key, value, ttl).Err()
+}
+
+func (store *RedisStore) Delete(key string, ctx context.Context) error {
+	return store.Client.WithContext(ctx).Del(store.Prefix + key).Err()
+}
@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps values for a limited time. Implementations are safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value stored under key and whether there was one.
+	Get(key string, ctx context.Context) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration, ctx context.Context) error
+	Delete(key string, ctx context.Context) error
+}
@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/windbnb/accomodation-service/clock"
+)
+
+// Strategy decides which of the available instances a call goes to.
+type Strategy string
+
+const (
+	RoundRobin       Strategy = "round-robin"
+	LeastConnections Strategy = "least-connections"
+)
+
+func ParseStrategy(name string) (Strategy, error) {
+	switch Strategy(name) {
+	case RoundRobin, LeastConnections:
+		return Strategy(name), nil
+	}
+	return "", fmt.Errorf("unknown balancing strategy %q", name)
+}
+
+// ParseEndpoints parses a comma separated list of base URLs such as
+// "http://user-service-1:8081,http://user-service-2:8081".
+func ParseEndpoints(rawUrls string) ([]*url.URL, error) {
+	urls := []*url.URL{}
+	for _, rawUrl := range strings.Split(rawUrls, ",") {
+		rawUrl = strings.TrimSpace(rawUrl)
+		if rawUrl == "" {
+			continue
+		}
+		parsedUrl, err := url.Parse(rawUrl)
+		if err != nil {
+			return nil, err
+		}
+		if parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https" || parsedUrl.Host == "" {
+			return nil, fmt.Errorf("endpoint %q has to be an absolute http or https url", rawUrl)
+		}
+		urls = append(urls, parsedUrl)
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("no endpoints given")
+	}
+	return urls, nil
+}
+
+type instance struct {
+	url          *url.URL
+	healthy      bool
+	active       int
+	failures     int
+	ejectedUntil time.Time
+}
+
+// Balancer spreads calls over the instances of a service. Instances failing
+// their health check are skipped, and an instance with EjectAfter consecutive
+// failed calls is ejected for EjectFor. When no instance is available calls
+// go to all of them, leaving it to the circuit breaker to stop a service that
+// is down as a whole.
+type Balancer struct {
+	Strategy   Strategy
+	EjectAfter int
+	EjectFor   time.Duration
+	HttpClient *http.Client
+	Clock      clock.Func
+
+	mutex     sync.Mutex
+	instances []*instance
+	next      int
+}
+
+func NewBalancer(urls []*url.URL, strategy Strategy) *Balancer {
+	balancer := &Balancer{Strategy: strategy, EjectAfter: 3, EjectFor: 30 * time.Second}
+	balancer.SetEndpoints(urls)
+	return balancer
+}
+
+// SetEndpoints replaces the instances, keeping the state of the ones that
+// stay.
+func (balancer *Balancer) SetEndpoints(urls []*url.URL) {
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	known := map[string]*instance{}
+	for _, existing := range balancer.instances {
+		known[existing.url.String()] = existing
+	}
+
+	instances := make([]*instance, 0, len(urls))
+	for _, endpoint := range urls {
+		if existing, found := known[endpoint.String()]; found {
+			instances = append(instances, existing)
+			continue
+		}
+		instances = append(instances, &instance{url: endpoint, healthy: true})
+	}
+	balancer.instances = instances
+}
+
+// Endpoints returns the URLs of all instances, available or not.
+func (balancer *Balancer) Endpoints() []*url.URL {
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	urls := make([]*url.URL, 0, len(balancer.instances))
+	for _, instance := range balancer.instances {
+		urls = append(urls, instance.url)
+	}
+	return urls
+}
+
+// Pick chooses the instance for a call. The returned function has to be
+// called once the call is over, telling whether the instance failed it.
+func (balancer *Balancer) Pick() (*url.URL, func(failed bool)) {
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	chosen := balancer.choose()
+	if chosen == nil {
+		return nil, func(failed bool) {}
+	}
+	chosen.active++
+
+	var once sync.Once
+	return chosen.url, func(failed bool) {
+		once.Do(func() { balancer.report(chosen, failed) })
+	}
+}
+
+func (balancer *Balancer) choose() *instance {
+	candidates := balancer.available()
+	if len(candidates) == 0 {
+		candidates = balancer.instances
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	start := balancer.next % len(candidates)
+	balancer.next++
+	chosen := candidates[start]
+	if balancer.Strategy == LeastConnections {
+		for i := 1; i < len(candidates); i++ {
+			candidate := candidates[(start+i)%len(candidates)]
+			if candidate.active < chosen.active {
+				chosen = candidate
+			}
+		}
+	}
+	return chosen
+}
+
+func (balancer *Balancer) available() []*instance {
+	now := balancer.Clock.Now()
+	available := make([]*instance, 0, len(balancer.instances))
+	for _, instance := range balancer.instances {
+		if instance.healthy && !now.Before(instance.ejectedUntil) {
+			available = append(available, instance)
+		}
+	}
+	return available
+}
+
+func (balancer *Balancer) report(chosen *instance, failed bool) {
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	chosen.active--
+	if !failed {
+		chosen.failures = 0
+		return
+	}
+	chosen.failures++
+	if balancer.EjectAfter > 0 && chosen.failures >= balancer.EjectAfter {
+		log.Printf("ejecting %s for %s after %d failed calls", chosen.url, balancer.EjectFor, chosen.failures)
+		chosen.ejectedUntil = balancer.Clock.Now().Add(balancer.EjectFor)
+		chosen.failures = 0
+	}
+}
+
+// CheckHealth sends a GET to path on every instance and takes those not
+// answering with a 2xx status out of rotation until they pass again.
+func (balancer *Balancer) CheckHealth(path string, ctx context.Context) {
+	var wait sync.WaitGroup
+	for _, endpoint := range balancer.Endpoints() {
+		wait.Add(1)
+		go func(endpoint *url.URL) {
+			defer wait.Done()
+			healthy := balancer.probe(endpoint, path, ctx)
+
+			balancer.mutex.Lock()
+			defer balancer.mutex.Unlock()
+			for _, instance := range balancer.instances {
+				if instance.url == endpoint {
+					if instance.healthy != healthy {
+						log.Printf("%s is now healthy: %t", endpoint, healthy)
+					}
+					instance.healthy = healthy
+				}
+			}
+		}(endpoint)
+	}
+	wait.Wait()
+}
+
+func (balancer *Balancer) probe(endpoint *url.URL, path string, ctx context.Context) bool {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint.String(), "/")+path, nil)
+	if err != nil {
+		return false
+	}
+	response, err := balancer.httpClient().Do(request)
+	if err != nil {
+		return false
+	}
+	defer response.Body.Close()
+	return response.StatusCode >= 200 && response.StatusCode < 300
+}
+
+// RunHealthChecks checks the instances every interval until ctx is done. Each
+// round of checks has to finish within the interval.
+func (balancer *Balancer) RunHealthChecks(path string, interval time.Duration, ctx context.Context) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		balancer.CheckHealth(path, checkCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (balancer *Balancer) httpClient() *http.Client {
+	if balancer.HttpClient == nil {
+		return http.DefaultClient
+	}
+	return balancer.HttpClient
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/accomodation-service/cache"
+	"github.com/windbnb/accomodation-service/clock"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+// UserService looks up users. *UserServiceClient implements it.
+type UserService interface {
+	GetUserById(userId uint, ctx context.Context) (model.UserResponseDTO, error)
+	AuthorizeHost(tokenString string, ctx context.Context) (model.UserResponseDTO, error)
+}
+
+type cachedUser struct {
+	User     model.UserResponseDTO `json:"user"`
+	CachedAt time.Time             `json:"cachedAt"`
+}
+
+// CachingUserService keeps users and the results of token authorization in
+// Store for TTL. Only successful lookups are cached. InvalidateUser drops
+// everything cached for a user, including the tokens resolved to them.
+// Should the store fail, the user service is asked directly.
+type CachingUserService struct {
+	UserService UserService
+	Store       cache.Store
+	TTL         time.Duration
+	Clock       clock.Func
+}
+
+func NewCachingUserService(userService UserService, store cache.Store, ttl time.Duration) *CachingUserService {
+	return &CachingUserService{UserService: userService, Store: store, TTL: ttl}
+}
+
+func (service *CachingUserService) GetUserById(userId uint, ctx context.Context) (model.UserResponseDTO, error) {
+	return service.cached(userKey(userId), ctx, func() (model.UserResponseDTO, error) {
+		return service.UserService.GetUserById(userId, ctx)
+	})
+}
+
+// AuthorizeHost caches under a hash of the token, so tokens are never stored
+// as they are.
+func (service *CachingUserService) AuthorizeHost(tokenString string, ctx context.Context) (model.UserResponseDTO, error) {
+	tokenHash := sha256.Sum256([]byte(tokenString))
+	return service.cached("token:"+hex.EncodeToString(tokenHash[:]), ctx, func() (model.UserResponseDTO, error) {
+		return service.UserService.AuthorizeHost(tokenString, ctx)
+	})
+}
+
+// InvalidateUser makes every cached entry for the user stale. Tokens are not
+// indexed by user, so instead of deleting them the time of invalidation is
+// kept and entries cached before it are ignored.
+func (service *CachingUserService) InvalidateUser(userId uint, ctx context.Context) error {
+	invalidatedAt := strconv.FormatInt(service.Clock.Now().UnixNano(), 10)
+	if err := service.Store.Set(invalidatedKey(userId), []byte(invalidatedAt), service.TTL, ctx); err != nil {
+		return err
+	}
+	return service.Store.Delete(userKey(userId), ctx)
+}
+
+func (service *CachingUserService) cached(key string, ctx context.Context, fetch func() (model.UserResponseDTO, error)) (model.UserResponseDTO, error) {
+	if user, found := service.lookup(key, ctx); found {
+		return user, nil
+	}
+
+	// Taken before fetching, so that an invalidation arriving during the
+	// fetch makes the result stale.
+	fetchedAt := service.Clock.Now()
+	user, err := fetch()
+	if err != nil {
+		return user, err
+	}
+
+	value, _ := json.Marshal(cachedUser{User: user, CachedAt: fetchedAt})
+	if err := service.Store.Set(key, value, service.TTL, ctx); err != nil {
+		log.Printf("caching %s failed: %s", key, err)
+	}
+	return user, nil
+}
+
+func (service *CachingUserService) lookup(key string, ctx context.Context) (model.UserResponseDTO, bool) {
+	value, found, err := service.Store.Get(key, ctx)
+	if err != nil {
+		log.Printf("reading %s from cache failed: %s", key, err)
+		return model.UserResponseDTO{}, false
+	}
+	if !found {
+		return model.UserResponseDTO{}, false
+	}
+
+	var entry cachedUser
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return model.UserResponseDTO{}, false
+	}
+
+	invalidatedAt, found, err := service.Store.Get(invalidatedKey(entry.User.Id), ctx)
+	if err != nil {
+		log.Printf("reading invalidation of user %d from cache failed: %s", entry.User.Id, err)
+		return model.UserResponseDTO{}, false
+	}
+	if found {
+		nanos, _ := strconv.ParseInt(string(invalidatedAt), 10, 64)
+		if !entry.CachedAt.After(time.Unix(0, nanos)) {
+			return model.UserResponseDTO{}, false
+		}
+	}
+	return entry.User, true
+}
+
+func userKey(userId uint) string {
+	return fmt.Sprintf("user:%d", userId)
+}
+
+func invalidatedKey(userId uint) string {
+	return fmt.Sprintf("user-invalidated:%d", userId)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/windbnb/accomodation-service/clock"
+)
+
+// ErrCircuitOpen is returned without calling the user service while it is
+// considered down.
+var ErrCircuitOpen = errors.New("user service is unavailable, circuit breaker is open")
+
+type circuitState int
+
+const (
+	closed circuitState = iota
+	open
+	halfOpen
+)
+
+// CircuitBreaker stops calls to a service after FailureThreshold consecutive
+// failures. Once OpenTimeout has passed a single trial call is let through;
+// its success closes the circuit again and its failure opens it once more.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	Clock            clock.Func
+
+	mutex    sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{FailureThreshold: failureThreshold, OpenTimeout: openTimeout}
+}
+
+// Allow reports whether a call may be made now.
+func (breaker *CircuitBreaker) Allow() error {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.state == closed {
+		return nil
+	}
+	// While half open only the trial call is let through. Should it never
+	// report back, another trial is allowed once OpenTimeout passes again.
+	if breaker.Clock.Now().Sub(breaker.openedAt) < breaker.OpenTimeout {
+		return ErrCircuitOpen
+	}
+	breaker.state = halfOpen
+	breaker.openedAt = breaker.Clock.Now()
+	return nil
+}
+
+func (breaker *CircuitBreaker) Success() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.state = closed
+	breaker.failures = 0
+}
+
+func (breaker *CircuitBreaker) Failure() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.failures++
+	if breaker.state == halfOpen || breaker.failures >= breaker.FailureThreshold {
+		breaker.state = open
+		breaker.openedAt = breaker.Clock.Now()
+	}
+}
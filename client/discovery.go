@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SRVResolver looks up DNS SRV records. *net.Resolver implements it.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// SRVDiscovery finds the instances of a service through the SRV records of
+// Name, for example "_http._tcp.user-service.windbnb.local". Only the
+// records with the best priority are used; the others are meant as backups.
+type SRVDiscovery struct {
+	Name     string
+	Scheme   string
+	Resolver SRVResolver
+}
+
+func NewSRVDiscovery(name string) *SRVDiscovery {
+	return &SRVDiscovery{Name: name, Scheme: "http", Resolver: net.DefaultResolver}
+}
+
+func (discovery *SRVDiscovery) Discover(ctx context.Context) ([]*url.URL, error) {
+	_, records, err := discovery.Resolver.LookupSRV(ctx, "", "", discovery.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("there are no SRV records for %s", discovery.Name)
+	}
+
+	bestPriority := records[0].Priority
+	for _, record := range records {
+		if record.Priority < bestPriority {
+			bestPriority = record.Priority
+		}
+	}
+
+	urls := []*url.URL{}
+	for _, record := range records {
+		if record.Priority != bestPriority {
+			continue
+		}
+		host := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+		urls = append(urls, &url.URL{Scheme: discovery.Scheme, Host: host})
+	}
+	return urls, nil
+}
+
+// RunDiscovery refreshes the instances of balancer every interval until ctx
+// is done. A failed lookup keeps the instances found before.
+func (discovery *SRVDiscovery) RunDiscovery(balancer *Balancer, interval time.Duration, ctx context.Context) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		urls, err := discovery.Discover(ctx)
+		if err != nil {
+			log.Printf("discovering %s failed: %s", discovery.Name, err)
+			continue
+		}
+		balancer.SetEndpoints(urls)
+	}
+}
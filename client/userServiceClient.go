@@ -1,37 +1,180 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/windbnb/accomodation-service/model"
-	"github.com/windbnb/accomodation-service/util"
+	"github.com/windbnb/accomodation-service/tracer"
 )
 
-func GetUserById(userId uint) (model.UserResponseDTO, error) {
-	response, err := http.Get(util.BaseUserServicePathRoundRobin.Next().Host + "/api/users/" + fmt.Sprint(userId))
+// Endpoints picks the instance each call goes to. The returned function is
+// called once the call is over, telling whether the instance failed it.
+// *Balancer implements it.
+type Endpoints interface {
+	Pick() (*url.URL, func(failed bool))
+}
+
+// StatusError is returned when the user service answers with a status other
+// than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *StatusError) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("user service responded with status %d", err.StatusCode)
+	}
+	return fmt.Sprintf("user service responded with status %d: %s", err.StatusCode, err.Message)
+}
+
+// retryable reports whether the same call may succeed when tried again.
+func (err *StatusError) retryable() bool {
+	return err.StatusCode >= http.StatusInternalServerError || err.StatusCode == http.StatusTooManyRequests
+}
+
+// UserServiceClient calls the user service. Every attempt has its own
+// Timeout, idempotent calls are retried up to MaxRetries times on network
+// errors and 5xx responses, and Breaker stops calling a user service that
+// keeps failing.
+type UserServiceClient struct {
+	Endpoints    Endpoints
+	HttpClient   *http.Client
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	Breaker      *CircuitBreaker
+}
+
+func NewUserServiceClient(endpoints Endpoints) *UserServiceClient {
+	return &UserServiceClient{
+		Endpoints:    endpoints,
+		HttpClient:   &http.Client{Transport: &tracer.Transport{}},
+		Timeout:      5 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 100 * time.Millisecond,
+		Breaker:      NewCircuitBreaker(5, 30*time.Second),
+	}
+}
+
+func (client *UserServiceClient) GetUserById(userId uint, ctx context.Context) (model.UserResponseDTO, error) {
+	span := tracer.StartSpanFromContext(ctx, "getUserByIdClient")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	var userResponse model.UserResponseDTO
+	err := client.call(http.MethodGet, "/api/users/"+fmt.Sprint(userId), nil, true, &userResponse, ctx)
 	if err != nil {
-		return model.UserResponseDTO{}, err
+		tracer.LogError(span, err)
 	}
+	return userResponse, err
+}
+
+// AuthorizeHost resolves the user a token belongs to. It only reads, so it is
+// retried like a GET even though the user service exposes it as a POST.
+func (client *UserServiceClient) AuthorizeHost(tokenString string, ctx context.Context) (model.UserResponseDTO, error) {
+	span := tracer.StartSpanFromContext(ctx, "authorizeHostClient")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	var userResponse model.UserResponseDTO
-	json.NewDecoder(response.Body).Decode(&userResponse)
-	return userResponse, nil
+	headers := http.Header{"Authorization": []string{tokenString}}
+	err := client.call(http.MethodPost, "/api/users/authorize/host", headers, true, &userResponse, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+	}
+	return userResponse, err
 }
 
-func AuthorizeHost(tokenString string) (model.UserResponseDTO, error) {
-	url := util.BaseUserServicePathRoundRobin.Next().Host + "/api/users/authorize/host"
-	req, err := http.NewRequest("POST", url, nil)
-	req.Header.Add("Authorization", tokenString)
-	client := &http.Client{}
-	response, err := client.Do(req)
+func (client *UserServiceClient) call(method string, path string, headers http.Header, idempotent bool, result interface{}, ctx context.Context) error {
+	attempts := 1
+	if idempotent {
+		attempts += client.MaxRetries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(client.RetryBackoff * time.Duration(1<<(attempt-1))):
+			}
+		}
+
+		if err = client.Breaker.Allow(); err != nil {
+			return err
+		}
+		endpoint, done := client.Endpoints.Pick()
+		if endpoint == nil {
+			return errors.New("there are no user service instances")
+		}
+		err = client.attempt(endpoint, method, path, headers, result, ctx)
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the user service.
+			done(false)
+			return err
+		}
+		done(isServiceFailure(err))
+		if !isServiceFailure(err) {
+			client.Breaker.Success()
+			return err
+		}
+		client.Breaker.Failure()
+	}
+	return err
+}
 
+func (client *UserServiceClient) attempt(endpoint *url.URL, method string, path string, headers http.Header, result interface{}, ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(endpoint.String(), "/")+path, nil)
 	if err != nil {
-		return model.UserResponseDTO{}, err
+		return err
+	}
+	for name, values := range headers {
+		request.Header[name] = values
 	}
+	request.Header.Set("Accept", "application/json")
 
-	var userResponse model.UserResponseDTO
-	json.NewDecoder(response.Body).Decode(&userResponse)
-	return userResponse, nil
+	response, err := client.HttpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return statusError(response)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func statusError(response *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	var errorResponse model.ErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Message != "" {
+		return &StatusError{StatusCode: response.StatusCode, Message: errorResponse.Message}
+	}
+	return &StatusError{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(body))}
+}
+
+// isServiceFailure reports whether an error means the user service itself is
+// failing, as opposed to rejecting the request.
+func isServiceFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if statusErr, ok := err.(*StatusError); ok {
+		return statusErr.retryable()
+	}
+	return true
 }
@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/windbnb/accomodation-service/clock"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+var defaultHttpClient = &http.Client{Timeout: 10 * time.Second, Transport: &tracer.Transport{}}
+
+// HTTPProvider fetches a Rates table from an exchange rate API and caches it
+// for TTL. When a refresh fails the previous table keeps being used, so a
+// short outage of the API does not break pricing, and the API is not asked
+// again before RetryInterval has passed.
+type HTTPProvider struct {
+	Url           string
+	TTL           time.Duration
+	RetryInterval time.Duration
+	HttpClient    *http.Client
+	Clock         clock.Func
+
+	mutex      sync.Mutex
+	rates      Rates
+	fetchedAt  time.Time
+	failedAt   time.Time
+	failure    error
+	refreshing chan struct{}
+}
+
+func NewHTTPProvider(url string, ttl time.Duration) *HTTPProvider {
+	return &HTTPProvider{Url: url, TTL: ttl, RetryInterval: time.Minute}
+}
+
+func (provider *HTTPProvider) Rate(from string, to string, ctx context.Context) (float64, error) {
+	rates, err := provider.currentRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rates.Rate(from, to)
+}
+
+// currentRates returns the cached table, refreshing it when it is older than
+// TTL. Only one caller fetches at a time and never while holding the mutex;
+// the others meanwhile use the table they have, or wait for the first one.
+func (provider *HTTPProvider) currentRates(ctx context.Context) (Rates, error) {
+	for {
+		provider.mutex.Lock()
+		now := provider.Clock.Now()
+		if !provider.fetchedAt.IsZero() && now.Sub(provider.fetchedAt) < provider.TTL {
+			rates := provider.rates
+			provider.mutex.Unlock()
+			return rates, nil
+		}
+		if provider.refreshing == nil && (provider.failedAt.IsZero() || now.Sub(provider.failedAt) >= provider.RetryInterval) {
+			provider.refreshing = make(chan struct{})
+			provider.mutex.Unlock()
+			return provider.refresh(ctx)
+		}
+
+		rates, fetchedAt, failure, refreshing := provider.rates, provider.fetchedAt, provider.failure, provider.refreshing
+		provider.mutex.Unlock()
+		if !fetchedAt.IsZero() {
+			return rates, nil
+		}
+		if refreshing == nil {
+			return Rates{}, failure
+		}
+		select {
+		case <-refreshing:
+		case <-ctx.Done():
+			return Rates{}, ctx.Err()
+		}
+	}
+}
+
+// refresh fetches the table for a caller that has set refreshing, and falls
+// back to the cached table when that fails.
+func (provider *HTTPProvider) refresh(ctx context.Context) (Rates, error) {
+	rates, err := provider.fetch(ctx)
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	close(provider.refreshing)
+	provider.refreshing = nil
+
+	if err == nil {
+		provider.rates = rates
+		provider.fetchedAt = provider.Clock.Now()
+		provider.failedAt = time.Time{}
+		return rates, nil
+	}
+	// A caller giving up is no reason to stop asking the API.
+	if ctx.Err() == nil {
+		provider.failedAt = provider.Clock.Now()
+		provider.failure = err
+	}
+	if provider.fetchedAt.IsZero() {
+		return Rates{}, err
+	}
+	log.Printf("refreshing exchange rates failed, using rates from %s: %s", provider.fetchedAt.Format(time.RFC3339), err)
+	return provider.rates, nil
+}
+
+func (provider *HTTPProvider) fetch(ctx context.Context) (Rates, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.Url, nil)
+	if err != nil {
+		return Rates{}, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := provider.httpClient().Do(request)
+	if err != nil {
+		return Rates{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Rates{}, fmt.Errorf("exchange rate API responded with status %d", response.StatusCode)
+	}
+
+	var rates Rates
+	if err := json.NewDecoder(response.Body).Decode(&rates); err != nil {
+		return Rates{}, err
+	}
+	if err := rates.validate(); err != nil {
+		return Rates{}, err
+	}
+	return rates, nil
+}
+
+func (provider *HTTPProvider) httpClient() *http.Client {
+	if provider.HttpClient != nil {
+		return provider.HttpClient
+	}
+	return defaultHttpClient
+}
@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+)
+
+// Provider gives the exchange rate between two currencies, that is the price
+// of one unit of the from currency in the to currency.
+type Provider interface {
+	Rate(from string, to string, ctx context.Context) (float64, error)
+}
+
+// Rates is a table of exchange rates against a single base currency, in the
+// format served by common exchange rate APIs:
+//
+//	{"base": "EUR", "rates": {"RSD": 117.2, "USD": 1.08}}
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rate returns the rate from one currency to another, crossing through the
+// base currency when neither of them is the base.
+func (rates Rates) Rate(from string, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	fromRate, fromFound := rates.againstBase(from)
+	toRate, toFound := rates.againstBase(to)
+	if !fromFound || !toFound {
+		return 0, errors.New("there is no exchange rate from " + from + " to " + to)
+	}
+	return toRate / fromRate, nil
+}
+
+func (rates Rates) againstBase(currency string) (float64, bool) {
+	if currency == rates.Base {
+		return 1, true
+	}
+	rate, found := rates.Rates[currency]
+	return rate, found && rate > 0
+}
+
+func (rates Rates) validate() error {
+	if rates.Base == "" {
+		return errors.New("exchange rates have no base currency")
+	}
+	for currency, rate := range rates.Rates {
+		if rate <= 0 {
+			return errors.New("exchange rate for " + currency + " has to be positive")
+		}
+	}
+	return nil
+}
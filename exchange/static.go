@@ -0,0 +1,38 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+)
+
+// StaticProvider serves exchange rates loaded once from a JSON file.
+type StaticProvider struct {
+	rates Rates
+}
+
+func NewStaticProvider(rates Rates) *StaticProvider {
+	return &StaticProvider{rates: rates}
+}
+
+// LoadStaticProvider reads a Rates table from the file at path.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rates Rates
+	if err := json.NewDecoder(file).Decode(&rates); err != nil {
+		return nil, err
+	}
+	if err := rates.validate(); err != nil {
+		return nil, err
+	}
+	return NewStaticProvider(rates), nil
+}
+
+func (provider *StaticProvider) Rate(from string, to string, ctx context.Context) (float64, error) {
+	return provider.rates.Rate(from, to)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func (h *Handler) CreateAvailabilityRule(w http.ResponseWriter, r *http.Request) {
+	createForHost(h, w, r, "createAvailabilityRuleHandler", "availability rule", util.FromCreateAvailabilityRuleDTOToAvailabilityRule, h.Service.CreateAvailabilityRule, (*model.AvailabilityRule).ToDTO)
+}
+
+func (h *Handler) DeleteAvailabilityRule(w http.ResponseWriter, r *http.Request) {
+	h.deleteForHost(w, r, "deleteAvailabilityRuleHandler", "availability rule", h.Service.DeleteAvailabilityRule)
+}
+
+func (h *Handler) GetAvailabilityRulesForAccomodation(w http.ResponseWriter, r *http.Request) {
+	listForAccomodation(h, w, r, "getAvailabilityRulesForAccomodationHandler", "availability rules", h.Service.GetAvailabilityRulesForAccomodation)
+}
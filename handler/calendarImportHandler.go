@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/windbnb/accomodation-service/auth"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (h *Handler) ImportCalendarFromUrl(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("importCalendarFromUrlHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling import calendar from url at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var createCalendarImportDTO model.CreateCalendarImportDTO
+	if err := json.NewDecoder(r.Body).Decode(&createCalendarImportDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	calendarImport, err := h.Service.ImportCalendarFromUrl(uint(accomodationId), userResponse.Id, createCalendarImportDTO.Url, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(calendarImport.ToDTO())
+}
+
+func (h *Handler) ImportCalendarFile(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("importCalendarFileHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling import calendar file at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	file, _, err := r.FormFile("calendar")
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "you have to provide a calendar file", StatusCode: http.StatusBadRequest})
+		return
+	}
+	defer file.Close()
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	calendarImport, err := h.Service.ImportCalendarFile(uint(accomodationId), userResponse.Id, file, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(calendarImport.ToDTO())
+}
+
+func (h *Handler) GetCalendarImports(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getCalendarImportsHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling get calendar imports at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	calendarImportsDTO, err := h.Service.GetCalendarImports(uint(accomodationId), userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(calendarImportsDTO)
+}
+
+func (h *Handler) DeleteCalendarImport(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("deleteCalendarImportHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling delete calendar import at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	calendarImportId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse calendar import id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	err = h.Service.DeleteCalendarImport(calendarImportId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusNotFound})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
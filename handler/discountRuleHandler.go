@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func (h *Handler) CreateDiscountRule(w http.ResponseWriter, r *http.Request) {
+	createForHost(h, w, r, "createDiscountRuleHandler", "discount rule", util.FromCreateDiscountRuleDTOToDiscountRule, h.Service.CreateDiscountRule, (*model.DiscountRule).ToDTO)
+}
+
+func (h *Handler) DeleteDiscountRule(w http.ResponseWriter, r *http.Request) {
+	h.deleteForHost(w, r, "deleteDiscountRuleHandler", "discount rule", h.Service.DeleteDiscountRule)
+}
+
+func (h *Handler) GetDiscountRulesForAccomodation(w http.ResponseWriter, r *http.Request) {
+	listForAccomodation(h, w, r, "getDiscountRulesForAccomodationHandler", "discount rules", h.Service.GetDiscountRulesForAccomodation)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func (h *Handler) CreateFee(w http.ResponseWriter, r *http.Request) {
+	createForHost(h, w, r, "createFeeHandler", "fee", util.FromCreateFeeDTOToFee, h.Service.CreateFee, (*model.Fee).ToDTO)
+}
+
+func (h *Handler) DeleteFee(w http.ResponseWriter, r *http.Request) {
+	h.deleteForHost(w, r, "deleteFeeHandler", "fee", h.Service.DeleteFee)
+}
+
+func (h *Handler) GetFeesForAccomodation(w http.ResponseWriter, r *http.Request) {
+	listForAccomodation(h, w, r, "getFeesForAccomodationHandler", "fees", h.Service.GetFeesForAccomodation)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,31 +12,35 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/opentracing/opentracing-go"
+	"github.com/windbnb/accomodation-service/auth"
 	"github.com/windbnb/accomodation-service/client"
 	"github.com/windbnb/accomodation-service/model"
 	"github.com/windbnb/accomodation-service/service"
 	"github.com/windbnb/accomodation-service/tracer"
 	"github.com/windbnb/accomodation-service/util"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Handler struct {
-	Service *service.AccomodationService
-	Tracer  opentracing.Tracer
-	Closer  io.Closer
+	Service       *service.AccomodationService
+	Tracer        trace.Tracer
+	Closer        io.Closer
+	Authenticator auth.Authenticator
+	// UserCache is nil when users are not cached.
+	UserCache *client.CachingUserService
 }
 
 func (handler *Handler) Healthcheck(w http.ResponseWriter, _ *http.Request) {
-    _, _ = fmt.Fprintln(w, "Healthy!")
+	_, _ = fmt.Fprintln(w, "Healthy!")
 }
 
 func (handler *Handler) Ready(w http.ResponseWriter, _ *http.Request) {
-    _, _ = fmt.Fprintln(w, "Ready!")
+	_, _ = fmt.Fprintln(w, "Ready!")
 }
 
-
 func (h *Handler) CreateAccomodation(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("createAccomodationHandler", h.Tracer, r)
 	defer span.Finish()
@@ -49,25 +54,31 @@ func (h *Handler) CreateAccomodation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, _ := strconv.ParseUint(r.MultipartForm.Value["userId"][0], 10, 32)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	// The owner is always the authenticated host, never a form field.
+	userResponse, _ := auth.UserFrom(r.Context())
 
-	userResponse, err := client.GetUserById(uint(userId))
+	newAccomodation, err := util.ParseMultipartAccomodation(r)
 	if err != nil {
 		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusBadGateway)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadGateway})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
-
-	if userResponse.Role != "HOST" {
+	newAccomodation.UserId = userResponse.Id
+	if err := h.Service.ValidateTurnover(newAccomodation); err != nil {
+		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusBadRequest})
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+	if err := h.Service.ValidatePricing(newAccomodation); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
-
-	newAccomodation := util.ParseMultipartAccomodation(r)
-	newAccomodation.UserId = uint(userId)
 	savedAccomodation := h.Service.SaveAccomodation(newAccomodation, ctx)
 
 	files := r.MultipartForm.File["images"]
@@ -82,7 +93,7 @@ func (h *Handler) CreateAccomodation(w http.ResponseWriter, r *http.Request) {
 
 	accomodationDTO := savedAccomodation.ToDTO()
 	for _, imageName := range fileNames {
-		h.Service.SaveAccomodationImage(model.AccomodationImage{ImageName: imageName, AccomodationID: savedAccomodation.ID})
+		h.Service.SaveAccomodationImage(model.AccomodationImage{ImageName: imageName, AccomodationID: savedAccomodation.ID}, ctx)
 		accomodationDTO.Images = append(accomodationDTO.Images, imageName)
 	}
 
@@ -100,7 +111,7 @@ func (h *Handler) UpdateAccommodationAcceptReservationType(w http.ResponseWriter
 	params := mux.Vars(r)
 	accomodationId, _ := strconv.Atoi(params["id"])
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	var acceptReservationType *model.AcceptReservationTypeDTO
 	err := json.NewDecoder(r.Body).Decode(&acceptReservationType)
@@ -111,22 +122,49 @@ func (h *Handler) UpdateAccommodationAcceptReservationType(w http.ResponseWriter
 		return
 	}
 
-	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	accommodation, err := h.Service.UpdateAccommodationAcceptReservationType(uint(accomodationId), acceptReservationType.AcceptReservationType, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
 
-	if userResponse.Role != "HOST" {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+	json.NewEncoder(w).Encode(accommodation)
+}
+
+func (h *Handler) UpdateCheckInOut(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("updateCheckInOutHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling update check-in and check-out at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
 		return
 	}
 
-	accommodation, err := h.Service.UpdateAccommodationAcceptReservationType(uint(accomodationId), acceptReservationType.AcceptReservationType, userResponse.Id, ctx)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	var checkInOutDTO model.CheckInOutDTO
+	if err := json.NewDecoder(r.Body).Decode(&checkInOutDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	accommodation, err := h.Service.UpdateCheckInOut(uint(accomodationId), checkInOutDTO, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -134,7 +172,87 @@ func (h *Handler) UpdateAccommodationAcceptReservationType(w http.ResponseWriter
 		return
 	}
 
-	json.NewEncoder(w).Encode(accommodation)
+	json.NewEncoder(w).Encode(accommodation.ToDTO())
+}
+
+func (h *Handler) UpdateOccupancyPricing(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("updateOccupancyPricingHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling update occupancy pricing at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	var occupancyPricingDTO model.OccupancyPricingDTO
+	if err := json.NewDecoder(r.Body).Decode(&occupancyPricingDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	accommodation, err := h.Service.UpdateOccupancyPricing(uint(accomodationId), occupancyPricingDTO, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(accommodation.ToDTO())
+}
+
+func (h *Handler) UpdatePreparationDays(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("updatePreparationDaysHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling update preparation days at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	var preparationDaysDTO model.PreparationDaysDTO
+	if err := json.NewDecoder(r.Body).Decode(&preparationDaysDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	accommodation, err := h.Service.UpdatePreparationDays(uint(accomodationId), preparationDaysDTO.PreparationDays, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(accommodation.ToDTO())
 }
 
 func (h *Handler) FindAccommodationById(w http.ResponseWriter, r *http.Request) {
@@ -148,7 +266,7 @@ func (h *Handler) FindAccommodationById(w http.ResponseWriter, r *http.Request)
 	params := mux.Vars(r)
 	accomodationId, _ := strconv.Atoi(params["id"])
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	accomodation, err := h.Service.FindAccomodationById(uint(accomodationId), ctx)
 	availalbleTerms, err2 := h.Service.FindAvailableTerms(uint(accomodationId), ctx)
@@ -213,38 +331,28 @@ func (h *Handler) CreatePrice(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var createPricesDTO []model.CreatePriceDTO
-	json.NewDecoder(r.Body).Decode(&createPricesDTO)
-
-	ctx := tracer.ContextWithSpan(context.Background(), span)
-
-	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&createPricesDTO); err != nil {
 		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
 
-	if userResponse.Role != "HOST" {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
-		return
-	}
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
 
 	var pricesDTO []model.PriceDTO
 
 	for _, createPriceDTO := range createPricesDTO {
 		newPrice := util.FromCreatePriceDTOToPrice(createPriceDTO)
-		newPrice.Active = true
-		_, err := h.Service.FindAccomodationById(newPrice.AccomodationID, ctx)
+		savedPrice, err := h.Service.SavePrice(newPrice, userResponse.Id, ctx)
 		if err != nil {
 			tracer.LogError(span, err)
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 			return
 		}
-		savedPrice := h.Service.SavePrice(newPrice)
 		priceDTO := savedPrice.ToDTO()
 		pricesDTO = append(pricesDTO, priceDTO)
 	}
@@ -265,13 +373,20 @@ func (h *Handler) UpdatePrice(w http.ResponseWriter, r *http.Request) {
 	priceId, _ := strconv.ParseUint(params["id"], 10, 32)
 
 	var updatePriceDTO model.UpdatePriceDTO
-	json.NewDecoder(r.Body).Decode(&updatePriceDTO)
+	if err := json.NewDecoder(r.Body).Decode(&updatePriceDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	userResponse, _ := auth.UserFrom(r.Context())
 
 	newPrice := util.FromUpdatePriceDTOToPrice(updatePriceDTO)
 
-	savedPrice, err := h.Service.UpdatePrice(newPrice, priceId, ctx)
+	savedPrice, err := h.Service.UpdatePrice(newPrice, priceId, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -295,9 +410,11 @@ func (h *Handler) DeletePrice(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	priceId, _ := strconv.ParseUint(params["id"], 10, 32)
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	err := h.Service.DeletePrice(priceId, ctx)
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	err := h.Service.DeletePrice(priceId, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusNotFound)
@@ -318,37 +435,28 @@ func (h *Handler) CreateAvailableTerm(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var createAvailableTermsDTO []model.CreateAvailableTermDTO
-	json.NewDecoder(r.Body).Decode(&createAvailableTermsDTO)
-
-	ctx := tracer.ContextWithSpan(context.Background(), span)
-
-	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&createAvailableTermsDTO); err != nil {
 		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
 
-	if userResponse.Role != "HOST" {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
-		return
-	}
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
 
 	var availableTermsDTO []model.AvailableTermDTO
 
 	for _, createAvailableTermDTO := range createAvailableTermsDTO {
 		newAvailableTerm := util.FromCreateAvailableTermDTOToAvailableTerm(createAvailableTermDTO)
-		_, err := h.Service.FindAccomodationById(newAvailableTerm.AccomodationID, ctx)
+		savedAvailableTerm, err := h.Service.SaveAvailableTerm(newAvailableTerm, userResponse.Id, ctx)
 		if err != nil {
 			tracer.LogError(span, err)
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 			return
 		}
-		savedAvailableTerm := h.Service.SaveAvailableTerm(newAvailableTerm, ctx)
 		availableTermDTO := savedAvailableTerm.ToDTO()
 		availableTermsDTO = append(availableTermsDTO, availableTermDTO)
 	}
@@ -369,28 +477,20 @@ func (h *Handler) UpdateAvailableTerm(w http.ResponseWriter, r *http.Request) {
 	availableTermId, _ := strconv.ParseUint(params["id"], 10, 32)
 
 	var updateAvailableTermDTO model.UpdateAvailableTermDTO
-	json.NewDecoder(r.Body).Decode(&updateAvailableTermDTO)
-
-	ctx := tracer.ContextWithSpan(context.Background(), span)
-
-	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&updateAvailableTermDTO); err != nil {
 		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
 
-	if userResponse.Role != "HOST" {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
-		return
-	}
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
 
 	newAvailableTerm := util.FromUpdateAvailableTermDTOToAvailableTerm(updateAvailableTermDTO)
 
-	savedAvailableTerm, err := h.Service.UpdateAvailableTerm(newAvailableTerm, availableTermId, ctx)
+	savedAvailableTerm, err := h.Service.UpdateAvailableTerm(newAvailableTerm, availableTermId, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -414,25 +514,12 @@ func (h *Handler) DeleteAvailableTerm(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	availableTermId, _ := strconv.ParseUint(params["id"], 10, 32)
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
-
-	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
-	if err != nil {
-		tracer.LogError(span, err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
-		return
-	}
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	if userResponse.Role != "HOST" {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
-		return
-	}
+	userResponse, _ := auth.UserFrom(r.Context())
 
-	er := h.Service.DeleteAvailableTerm(availableTermId, ctx)
-	if er != nil {
+	err := h.Service.DeleteAvailableTerm(availableTermId, userResponse.Id, ctx)
+	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusNotFound})
@@ -452,9 +539,14 @@ func (h *Handler) CreateReservedTerm(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var createReservedTermDTO model.CreateReservedTermDTO
-	json.NewDecoder(r.Body).Decode(&createReservedTermDTO)
+	if err := json.NewDecoder(r.Body).Decode(&createReservedTermDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	newReservedTerm := util.FromCreateReservedTermDTOToReservedTerm(createReservedTermDTO)
 	_, err := h.Service.FindAccomodationById(newReservedTerm.AccomodationID, ctx)
@@ -464,7 +556,13 @@ func (h *Handler) CreateReservedTerm(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
 		return
 	}
-	savedReservedTerm := h.Service.SaveReservedTerm(newReservedTerm, ctx)
+	savedReservedTerm, err := h.Service.SaveReservedTerm(newReservedTerm, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
 	reservedTermDTO := savedReservedTerm.ToDTO()
 
 	json.NewEncoder(w).Encode(reservedTermDTO)
@@ -482,7 +580,7 @@ func (h *Handler) DeleteReservedTerm(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	reservedTermId, _ := strconv.ParseUint(params["id"], 10, 32)
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	err := h.Service.DeleteReservedTerm(reservedTermId, ctx)
 	if err != nil {
@@ -496,6 +594,45 @@ func (h *Handler) DeleteReservedTerm(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// HandleUserEvent drops what is cached about a user once the user service
+// reports them deleted or their role changed.
+func (h *Handler) HandleUserEvent(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("handleUserEventHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling user event at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	var userEventDTO model.UserEventDTO
+	if err := json.NewDecoder(r.Body).Decode(&userEventDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+	if userEventDTO.Type != model.USER_DELETED && userEventDTO.Type != model.ROLE_CHANGED {
+		err := fmt.Errorf("unknown user event type %s", userEventDTO.Type)
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	if h.UserCache != nil {
+		if err := h.UserCache.InvalidateUser(userEventDTO.UserId, ctx); err != nil {
+			tracer.LogError(span, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusInternalServerError})
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) DeleteHostAccomodation(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("deleteHostAccomodationHandler", h.Tracer, r)
 	defer span.Finish()
@@ -507,7 +644,7 @@ func (h *Handler) DeleteHostAccomodation(w http.ResponseWriter, r *http.Request)
 	params := mux.Vars(r)
 	hostId, err := strconv.ParseUint(params["hostId"], 10, 32)
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	if err != nil {
 		tracer.LogError(span, err)
@@ -537,15 +674,62 @@ func (h *Handler) SearchAccomodation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var searchAccomodationDTO model.SearchAccomodationDTO
-	json.NewDecoder(r.Body).Decode(&searchAccomodationDTO)
+	if err := json.NewDecoder(r.Body).Decode(&searchAccomodationDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+	if searchAccomodationDTO.Currency != "" && !model.IsCurrency(searchAccomodationDTO.Currency) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "currency " + searchAccomodationDTO.Currency + " is not supported", StatusCode: http.StatusBadRequest})
+		return
+	}
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	accomodationsDTO := h.Service.SearchAccomodations(searchAccomodationDTO, ctx)
 	json.NewEncoder(w).Encode(accomodationsDTO)
 
 }
 
+func (h *Handler) Quote(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("quoteHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling quote at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var searchAccomodationDTO model.SearchAccomodationDTO
+	if err := json.NewDecoder(r.Body).Decode(&searchAccomodationDTO); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	quote, err := h.Service.Quote(uint(accomodationId), searchAccomodationDTO, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(quote)
+}
 
 func (h *Handler) FindAccommodationsForHost(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("findAccomodationsForHostHandler", h.Tracer, r)
@@ -565,7 +749,7 @@ func (h *Handler) FindAccommodationsForHost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	accomodationsDTO := h.Service.FindAccommodationsForHost(uint(hostId), ctx)
 	json.NewEncoder(w).Encode(accomodationsDTO)
@@ -590,7 +774,7 @@ func (h *Handler) GetAvailableTermsForAccomodation(w http.ResponseWriter, r *htt
 		return
 	}
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	availableTermsDTO := h.Service.GetAvailableTermsForAccomodation(uint(accomodationId), ctx)
 	json.NewEncoder(w).Encode(availableTermsDTO)
@@ -615,10 +799,260 @@ func (h *Handler) GetPricesForAccomodation(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	pricesDTO := h.Service.GetPricesForAccomodation(uint(accomodationId), ctx)
 	json.NewEncoder(w).Encode(pricesDTO)
 
 }
 
+func (h *Handler) GetPriceHistoryForAccomodation(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getPriceHistoryForAccomodationHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling get price history for accomodation at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var at *time.Time
+	if atParam := r.URL.Query().Get("at"); atParam != "" {
+		parsedAt, err := time.Parse(time.RFC3339, atParam)
+		if err != nil {
+			tracer.LogError(span, err)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{Message: "at has to be an RFC 3339 timestamp", StatusCode: http.StatusBadRequest})
+			return
+		}
+		at = &parsedAt
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	pricesDTO := h.Service.GetPriceHistoryForAccomodation(uint(accomodationId), at, ctx)
+	json.NewEncoder(w).Encode(pricesDTO)
+
+}
+
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getCalendarHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling get calendar at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	from, fromErr := time.Parse(model.DateLayout, r.URL.Query().Get("from"))
+	to, toErr := time.Parse(model.DateLayout, r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		err := errors.New("from and to query parameters must be dates in yyyy-mm-dd format")
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	calendar, err := h.Service.GetCalendar(uint(accomodationId), from, to, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(calendar)
+}
+
+func (h *Handler) ExportCalendar(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("exportCalendarHandler", h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling export calendar at %s\n", r.URL.Path)),
+	)
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	includeAvailable, _ := strconv.ParseBool(r.URL.Query().Get("available"))
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	calendar, err := h.Service.ExportCalendar(uint(accomodationId), token, includeAvailable, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusNotFound})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"accomodation-%d.ics\"", accomodationId))
+	w.Write(calendar)
+}
+
+func (h *Handler) GetCalendarToken(w http.ResponseWriter, r *http.Request) {
+	h.handleCalendarToken(w, r, "getCalendarTokenHandler", h.Service.GetCalendarToken)
+}
+
+func (h *Handler) RegenerateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	h.handleCalendarToken(w, r, "regenerateCalendarTokenHandler", h.Service.RegenerateCalendarToken)
+}
+
+func (h *Handler) handleCalendarToken(w http.ResponseWriter, r *http.Request, spanName string, getToken func(uint, uint, context.Context) (string, error)) {
+	span := tracer.StartSpanFromRequest(spanName, h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling calendar token at %s\n", r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	calendarToken, err := getToken(uint(accomodationId), userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	json.NewEncoder(w).Encode(model.CalendarTokenDTO{Token: calendarToken})
+}
+
+// createForHost decodes a list of create DTOs, saves each converted entity
+// for the authenticated host and answers with the saved entities.
+func createForHost[Create any, Entity any, DTO any](h *Handler, w http.ResponseWriter, r *http.Request, spanName string, what string,
+	convert func(Create) Entity, save func(Entity, uint, context.Context) (Entity, error), toDTO func(*Entity) DTO) {
+	span := tracer.StartSpanFromRequest(spanName, h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling create %s at %s\n", what, r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	var createDTOs []Create
+	if err := json.NewDecoder(r.Body).Decode(&createDTOs); err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	savedDTOs := []DTO{}
+	for _, createDTO := range createDTOs {
+		saved, err := save(convert(createDTO), userResponse.Id, ctx)
+		if err != nil {
+			tracer.LogError(span, err)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+			return
+		}
+		savedDTOs = append(savedDTOs, toDTO(&saved))
+	}
+
+	json.NewEncoder(w).Encode(savedDTOs)
+}
+
+// deleteForHost deletes the entity with the id from the path when it belongs
+// to the authenticated host.
+func (h *Handler) deleteForHost(w http.ResponseWriter, r *http.Request, spanName string, what string,
+	remove func(uint64, uint, context.Context) error) {
+	span := tracer.StartSpanFromRequest(spanName, h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling delete %s at %s\n", what, r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse " + what + " id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse, _ := auth.UserFrom(r.Context())
+
+	err = remove(id, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusNotFound})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listForAccomodation answers with the entities of the accomodation whose id
+// is in the path.
+func listForAccomodation[DTO any](h *Handler, w http.ResponseWriter, r *http.Request, spanName string, what string,
+	list func(uint, context.Context) []DTO) {
+	span := tracer.StartSpanFromRequest(spanName, h.Tracer, r)
+	defer span.Finish()
+	span.LogFields(
+		tracer.LogString("handler", fmt.Sprintf("handling get %s for accomodation at %s\n", what, r.URL.Path)),
+	)
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accomodationId, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "cannot parse accomodation id", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	json.NewEncoder(w).Encode(list(uint(accomodationId), ctx))
+}
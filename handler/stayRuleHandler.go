@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func (h *Handler) CreateStayRule(w http.ResponseWriter, r *http.Request) {
+	createForHost(h, w, r, "createStayRuleHandler", "stay rule", util.FromCreateStayRuleDTOToStayRule, h.Service.CreateStayRule, (*model.StayRule).ToDTO)
+}
+
+func (h *Handler) DeleteStayRule(w http.ResponseWriter, r *http.Request) {
+	h.deleteForHost(w, r, "deleteStayRuleHandler", "stay rule", h.Service.DeleteStayRule)
+}
+
+func (h *Handler) GetStayRulesForAccomodation(w http.ResponseWriter, r *http.Request) {
+	listForAccomodation(h, w, r, "getStayRulesForAccomodationHandler", "stay rules", h.Service.GetStayRulesForAccomodation)
+}
@@ -0,0 +1,109 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) needed to
+// sync accomodation calendars with other platforms.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeLayout = "20060102T150405Z"
+
+	// maxLineLength is the number of octets after which content lines are folded.
+	maxLineLength = 75
+)
+
+type Event struct {
+	UID         string
+	Summary     string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+	Transparent bool
+	Cancelled   bool
+}
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Encode writes the calendar as an RFC 5545 document with CRLF line endings.
+func (calendar *Calendar) Encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendar.ProdID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if calendar.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeText(calendar.Name))
+	}
+
+	for _, event := range calendar.Events {
+		transparency := "OPAQUE"
+		if event.Transparent {
+			transparency = "TRANSPARENT"
+		}
+		status := "CONFIRMED"
+		if event.Cancelled {
+			status = "CANCELLED"
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeText(event.UID),
+			"DTSTAMP:"+event.Stamp.UTC().Format(dateTimeLayout),
+			"DTSTART:"+event.Start.UTC().Format(dateTimeLayout),
+			"DTEND:"+event.End.UTC().Format(dateTimeLayout),
+			"SUMMARY:"+escapeText(event.Summary),
+			"TRANSP:"+transparency,
+			"STATUS:"+status,
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := writer.WriteString(fold(line)); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// escapeText escapes the characters that have a special meaning in TEXT values.
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// fold splits a content line into chunks of at most 75 octets, as required by
+// RFC 5545, without breaking multi-byte characters.
+func fold(line string) string {
+	var builder strings.Builder
+	length := 0
+	for _, character := range line {
+		size := len(string(character))
+		if length+size > maxLineLength {
+			builder.WriteString("\r\n ")
+			length = 1
+		}
+		builder.WriteRune(character)
+		length += size
+	}
+	builder.WriteString("\r\n")
+	return builder.String()
+}
@@ -0,0 +1,240 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout          = "20060102"
+	localDateTimeLayout = "20060102T150405"
+)
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode parses the VEVENT components of an iCalendar document. Events that
+// are cancelled are marked as such so callers can decide whether to skip them.
+func Decode(r io.Reader) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var calendar Calendar
+	var event *Event
+	var properties []property
+	// nested counts the components open inside the current event, such as
+	// VALARM, whose properties describe them and not the event.
+	nested := 0
+	insideCalendar := false
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return Calendar{}, err
+		}
+
+		switch {
+		case event != nil && prop.name == "BEGIN":
+			nested++
+		case event != nil && nested > 0:
+			if prop.name == "END" {
+				nested--
+			}
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			insideCalendar = true
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			event = &Event{}
+			properties = nil
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if event == nil {
+				return Calendar{}, errors.New("unexpected END:VEVENT")
+			}
+			if err := event.apply(properties); err != nil {
+				return Calendar{}, err
+			}
+			calendar.Events = append(calendar.Events, *event)
+			event = nil
+		case event != nil:
+			properties = append(properties, prop)
+		case prop.name == "PRODID":
+			calendar.ProdID = prop.value
+		case prop.name == "X-WR-CALNAME":
+			calendar.Name = unescapeText(prop.value)
+		}
+	}
+
+	if !insideCalendar {
+		return Calendar{}, errors.New("document is not an iCalendar")
+	}
+	if event != nil {
+		return Calendar{}, errors.New("unterminated VEVENT")
+	}
+	return calendar, nil
+}
+
+func (event *Event) apply(properties []property) error {
+	var duration time.Duration
+	hasEnd, hasDuration, allDay := false, false, false
+	for _, prop := range properties {
+		var err error
+		switch prop.name {
+		case "UID":
+			event.UID = unescapeText(prop.value)
+		case "SUMMARY":
+			event.Summary = unescapeText(prop.value)
+		case "DTSTAMP":
+			event.Stamp, err = parseTime(prop)
+		case "DTSTART":
+			event.Start, err = parseTime(prop)
+			allDay = isDate(prop)
+		case "DTEND":
+			event.End, err = parseTime(prop)
+			hasEnd = true
+		case "DURATION":
+			duration, err = parseDuration(prop.value)
+			hasDuration = true
+		case "TRANSP":
+			event.Transparent = strings.EqualFold(prop.value, "TRANSPARENT")
+		case "STATUS":
+			event.Cancelled = strings.EqualFold(prop.value, "CANCELLED")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if event.Start.IsZero() {
+		return errors.New("event " + event.UID + " has no start date")
+	}
+	switch {
+	case hasEnd:
+	case hasDuration:
+		event.End = event.Start.Add(duration)
+	case allDay:
+		event.End = event.Start.AddDate(0, 0, 1)
+	default:
+		event.End = event.Start
+	}
+	if event.End.Before(event.Start) {
+		return errors.New("event " + event.UID + " ends before it starts")
+	}
+	return nil
+}
+
+// unfold joins folded content lines and accepts both CRLF and bare LF endings.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseProperty(line string) (property, error) {
+	separator := valueSeparator(line)
+	if separator < 0 {
+		return property{}, errors.New("malformed content line: " + line)
+	}
+
+	nameAndParams := strings.Split(line[:separator], ";")
+	prop := property{name: strings.ToUpper(nameAndParams[0]), params: map[string]string{}, value: line[separator+1:]}
+	for _, param := range nameAndParams[1:] {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) == 2 {
+			prop.params[strings.ToUpper(keyValue[0])] = strings.Trim(keyValue[1], `"`)
+		}
+	}
+	return prop, nil
+}
+
+// valueSeparator finds the colon that starts the property value, skipping
+// colons inside quoted parameter values.
+func valueSeparator(line string) int {
+	quoted := false
+	for i, character := range line {
+		switch character {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isDate(prop property) bool {
+	return strings.EqualFold(prop.params["VALUE"], "DATE") || len(prop.value) == len(dateLayout)
+}
+
+func parseTime(prop property) (time.Time, error) {
+	if isDate(prop) {
+		return time.ParseInLocation(dateLayout, prop.value, time.UTC)
+	}
+	if strings.HasSuffix(prop.value, "Z") {
+		return time.Parse(dateTimeLayout, prop.value)
+	}
+
+	location := time.UTC
+	if tzid, found := prop.params["TZID"]; found {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	return time.ParseInLocation(localDateTimeLayout, prop.value, location)
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, errors.New("malformed duration: " + value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		amount, _ := strconv.Atoi(match[i+2])
+		duration += time.Duration(amount) * unit
+	}
+	if match[1] == "-" {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+	return replacer.Replace(value)
+}
@@ -6,11 +6,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/rs/cors"
+	"github.com/windbnb/accomodation-service/auth"
+	"github.com/windbnb/accomodation-service/cache"
+	"github.com/windbnb/accomodation-service/client"
+	"github.com/windbnb/accomodation-service/exchange"
 	"github.com/windbnb/accomodation-service/handler"
 	"github.com/windbnb/accomodation-service/repository"
 	"github.com/windbnb/accomodation-service/router"
@@ -20,17 +25,27 @@ import (
 )
 
 func main() {
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	db := util.ConnectToDatabase()
+	repository.Instrument(db)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
 
 	tracer, closer := tracer.Init("accomodation-service")
-	opentracing.SetGlobalTracer(tracer)
+	accomodationService := &service.AccomodationService{
+		Repo:          &repository.Repository{Db: db},
+		ExchangeRates: exchangeRateProvider(),
+		ServiceToken:  os.Getenv("SERVICE_TOKEN")}
+	userService, userCache := configureUserService(backgroundCtx)
 	router := router.ConfigureRouter(&handler.Handler{
-		Tracer:  tracer,
-		Closer:  closer,
-		Service: &service.AccomodationService{Repo: &repository.Repository{Db: db}}})
+		Tracer:        tracer,
+		Closer:        closer,
+		Service:       accomodationService,
+		Authenticator: authenticator(userService),
+		UserCache:     userCache})
+
+	go accomodationService.RunCalendarSync(backgroundCtx, durationFromEnv("CALENDAR_SYNC_INTERVAL", time.Hour))
 
 	servicePath, servicePathFound := os.LookupEnv("SERVICE_PATH")
 	if !servicePathFound {
@@ -58,6 +73,7 @@ func main() {
 
 	<-quit
 
+	stopBackground()
 	defer db.Close()
 	log.Println("service shutting down ...")
 
@@ -68,6 +84,136 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal(err)
 	}
+	// flush the spans not exported yet
+	if err := closer.Close(); err != nil {
+		log.Println(err)
+	}
 	log.Println("server stopped")
 
 }
+
+// configureUserService caches users for USER_CACHE_TTL, one minute unless set, in
+// Redis at REDIS_ADDRESS or else in process, keeping at most USER_CACHE_SIZE
+// of them. A TTL of 0 turns caching off.
+func configureUserService(ctx context.Context) (client.UserService, *client.CachingUserService) {
+	userClient := client.NewUserServiceClient(userServiceBalancer(ctx))
+
+	ttl := durationFromEnv("USER_CACHE_TTL", time.Minute)
+	if ttl == 0 {
+		return userClient, nil
+	}
+
+	var store cache.Store
+	if redisAddress, redisAddressFound := os.LookupEnv("REDIS_ADDRESS"); redisAddressFound {
+		store = cache.NewRedisStore(redisAddress, "accomodation-service:")
+	} else {
+		size := 10000
+		if configuredSize, sizeFound := os.LookupEnv("USER_CACHE_SIZE"); sizeFound {
+			parsedSize, err := strconv.Atoi(configuredSize)
+			if err != nil {
+				log.Fatal(err)
+			}
+			size = parsedSize
+		}
+		store = cache.NewMemoryStore(size)
+	}
+
+	userCache := client.NewCachingUserService(userClient, store, ttl)
+	return userCache, userCache
+}
+
+// userServiceBalancer balances calls over the user service instances in
+// USER_SERVICE_URLS, or over those found through the SRV records named in
+// USER_SERVICE_SRV. USER_SERVICE_BALANCING picks round-robin or
+// least-connections, and with USER_SERVICE_HEALTH_PATH set every instance is
+// checked at that path.
+func userServiceBalancer(ctx context.Context) *client.Balancer {
+	strategy := client.RoundRobin
+	if name, nameFound := os.LookupEnv("USER_SERVICE_BALANCING"); nameFound {
+		parsedStrategy, err := client.ParseStrategy(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		strategy = parsedStrategy
+	}
+
+	var balancer *client.Balancer
+	if srvName, srvNameFound := os.LookupEnv("USER_SERVICE_SRV"); srvNameFound {
+		discovery := client.NewSRVDiscovery(srvName)
+		urls, err := discovery.Discover(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		balancer = client.NewBalancer(urls, strategy)
+		go discovery.RunDiscovery(balancer, durationFromEnv("USER_SERVICE_SRV_INTERVAL", 30*time.Second), ctx)
+	} else {
+		rawUrls, rawUrlsFound := os.LookupEnv("USER_SERVICE_URLS")
+		if !rawUrlsFound {
+			rawUrls = "http://nginx:8000"
+		}
+		urls, err := client.ParseEndpoints(rawUrls)
+		if err != nil {
+			log.Fatal(err)
+		}
+		balancer = client.NewBalancer(urls, strategy)
+	}
+
+	if healthPath, healthPathFound := os.LookupEnv("USER_SERVICE_HEALTH_PATH"); healthPathFound {
+		go balancer.RunHealthChecks(healthPath, durationFromEnv("USER_SERVICE_HEALTH_INTERVAL", 10*time.Second), ctx)
+	}
+	return balancer
+}
+
+// exchangeRateProvider uses the exchange rate API at EXCHANGE_RATES_URL, or
+// else the rates in the EXCHANGE_RATES_FILE JSON file. Without either, prices
+// are only shown in the currency of the host.
+func exchangeRateProvider() exchange.Provider {
+	if ratesUrl, ratesUrlFound := os.LookupEnv("EXCHANGE_RATES_URL"); ratesUrlFound {
+		return exchange.NewHTTPProvider(ratesUrl, durationFromEnv("EXCHANGE_RATES_TTL", time.Hour))
+	}
+
+	if ratesFile, ratesFileFound := os.LookupEnv("EXCHANGE_RATES_FILE"); ratesFileFound {
+		provider, err := exchange.LoadStaticProvider(ratesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return provider
+	}
+
+	return nil
+}
+
+// authenticator verifies JWTs locally against the keys at JWKS_URL. With
+// AUTH_REMOTE_FALLBACK set, the user service is asked instead while those
+// keys can not be fetched. Without JWKS_URL every token is checked by the
+// user service.
+func authenticator(userService client.UserService) auth.Authenticator {
+	jwksUrl, jwksUrlFound := os.LookupEnv("JWKS_URL")
+	if !jwksUrlFound {
+		return userService.AuthorizeHost
+	}
+
+	verifier := &auth.JWTVerifier{
+		Keys:     auth.NewKeySet(jwksUrl, durationFromEnv("JWKS_REFRESH_INTERVAL", time.Hour)),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE")}
+
+	if fallback, _ := strconv.ParseBool(os.Getenv("AUTH_REMOTE_FALLBACK")); fallback {
+		return auth.WithFallback(verifier.Authenticate, userService.AuthorizeHost)
+	}
+	return verifier.Authenticate
+}
+
+// durationFromEnv parses the duration in the given environment variable,
+// such as "90s" or "1h", falling back to defaultDuration when it is not set.
+func durationFromEnv(name string, defaultDuration time.Duration) time.Duration {
+	value, valueFound := os.LookupEnv(name)
+	if !valueFound {
+		return defaultDuration
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return duration
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,7 +13,8 @@ import (
 // wrapper for ResponseWriter class
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	bytesWritten int
 }
 
 func (r *responseWriter) WriteHeader(status int) {
@@ -20,6 +22,12 @@ func (r *responseWriter) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 }
 
+func (r *responseWriter) Write(body []byte) (int, error) {
+	written, err := r.ResponseWriter.Write(body)
+	r.bytesWritten += written
+	return written, err
+}
+
 var (
 	// The Prometheus metrics that will be exposed.
 	httpHits = prometheus.NewCounter(
@@ -64,6 +72,43 @@ var (
 		},
 		[]string{"visitor"})
 
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests in seconds.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status"})
+
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests.",
+		},
+		[]string{"route", "method", "status"})
+
+	httpResponseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP response bodies in bytes.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		},
+		[]string{"route", "method", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served.",
+		})
+
+	databaseQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "database_query_duration_seconds",
+			Help:    "Duration of database queries in seconds.",
+			Buckets: []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		},
+		[]string{"operation", "table", "status"})
+
 	// Add all metrics that will be resisted
 	metricsList = []prometheus.Collector{
 		httpHits,
@@ -72,6 +117,11 @@ var (
 		uniqueVisitorCounter,
 		httpStatusNotFoundCounter,
 		trafficAccumulationMetric,
+		httpRequestDuration,
+		httpRequestsTotal,
+		httpResponseSize,
+		httpRequestsInFlight,
+		databaseQueryDuration,
 	}
 
 	// Prometheus Registry to register metrics.
@@ -83,19 +133,37 @@ func init() {
 	prometheusRegistry.MustRegister(metricsList...)
 }
 
+// ObserveDatabaseQuery records how long a query of the given operation, such
+// as "query" or "update", took on table.
+func ObserveDatabaseQuery(operation string, table string, duration time.Duration, failed bool) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	databaseQueryDuration.WithLabelValues(operation, table, status).Observe(duration.Seconds())
+}
+
 func MetricsHandler() http.Handler {
 	return promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{})
 }
 
 func MetricProxy(f func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		route := mux.CurrentRoute(r)
-		path, _ := route.GetPathTemplate()
+		path := routeTemplate(r)
 
 		userAgent := r.Header.Get("User-Agent")
-		rw := &responseWriter{w, http.StatusOK}
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+		startedAt := time.Now()
 		f(rw, r) // original function call
+		duration := time.Since(startedAt)
+
+		status := strconv.Itoa(rw.statusCode)
+		httpRequestDuration.WithLabelValues(path, r.Method, status).Observe(duration.Seconds())
+		httpRequestsTotal.WithLabelValues(path, r.Method, status).Inc()
+		httpResponseSize.WithLabelValues(path, r.Method, status).Observe(float64(rw.bytesWritten))
 
 		httpHits.Inc()
 
@@ -117,3 +185,17 @@ func MetricProxy(f func(http.ResponseWriter, *http.Request)) func(http.ResponseW
 		}
 	}
 }
+
+// routeTemplate returns the template of the route serving r, such as
+// "/api/accomodation/{id}", so that requests for different ids share labels.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	return path
+}
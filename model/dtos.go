@@ -18,6 +18,14 @@ type AccomodationDTO struct {
 	UserId                uint                  `json:"userId"`
 	AcceptReservationType AcceptReservationType `json:"acceptReservationType"`
 	PriceType             PriceType             `json:"priceType"`
+	CheckInTime           string                `json:"checkInTime"`
+	CheckOutTime          string                `json:"checkOutTime"`
+	CheckInDays           []string              `json:"checkInDays"`
+	PreparationDays       uint                  `json:"preparationDays"`
+	TimeZone              string                `json:"timeZone"`
+	BaseOccupancy         uint                  `json:"baseOccupancy"`
+	ExtraGuestFee         Money                 `json:"extraGuestFee"`
+	Currency              string                `json:"currency"`
 }
 
 type AccommodationBasicDTO struct {
@@ -42,6 +50,20 @@ const (
 	GUEST UserRole = "GUEST"
 )
 
+type UserEventType string
+
+const (
+	USER_DELETED UserEventType = "USER_DELETED"
+	ROLE_CHANGED UserEventType = "ROLE_CHANGED"
+)
+
+// UserEventDTO is sent by the user service when a user changes in a way that
+// makes what other services know about them stale.
+type UserEventDTO struct {
+	Type   UserEventType `json:"type"`
+	UserId uint          `json:"userId"`
+}
+
 type UserResponseDTO struct {
 	Id       uint     `json:"id"`
 	Email    string   `json:"email"`
@@ -55,7 +77,7 @@ type UserResponseDTO struct {
 type CreatePriceDTO struct {
 	StartDate      time.Time     `json:"startDate"`
 	EndDate        time.Time     `json:"endDate"`
-	Value          float32       `json:"value"`
+	Value          Money         `json:"value"`
 	PriceDuration  PriceDuration `json:"priceDuration"`
 	AccomodationID uint          `json:"accomodationId"`
 }
@@ -64,16 +86,20 @@ type UpdatePriceDTO struct {
 	Id        uint      `json:"id"`
 	StartDate time.Time `json:"startDate"`
 	EndDate   time.Time `json:"endDate"`
-	Value     float32   `json:"value"`
+	Value     Money     `json:"value"`
 }
 
 type PriceDTO struct {
-	Id             uint          `json:"id"`
-	StartDate      time.Time     `json:"startDate"`
-	EndDate        time.Time     `json:"endDate"`
-	Value          float32       `json:"value"`
-	PriceDuration  PriceDuration `json:"priceDuration"`
-	AccomodationID uint          `json:"accomodationId"`
+	Id              uint          `json:"id"`
+	StartDate       time.Time     `json:"startDate"`
+	EndDate         time.Time     `json:"endDate"`
+	Value           Money         `json:"value"`
+	PriceDuration   PriceDuration `json:"priceDuration"`
+	AccomodationID  uint          `json:"accomodationId"`
+	Active          bool          `json:"active"`
+	PreviousPriceId uint          `json:"previousPriceId,omitempty"`
+	ActiveFrom      time.Time     `json:"activeFrom"`
+	ActiveTo        *time.Time    `json:"activeTo,omitempty"`
 }
 
 type CreateAvailableTermDTO struct {
@@ -112,18 +138,186 @@ type AcceptReservationTypeDTO struct {
 	AcceptReservationType AcceptReservationType `json:"acceptReservationType"`
 }
 
+type OccupancyPricingDTO struct {
+	PriceType     PriceType `json:"priceType"`
+	BaseOccupancy uint      `json:"baseOccupancy"`
+	ExtraGuestFee Money     `json:"extraGuestFee"`
+}
+
+type PreparationDaysDTO struct {
+	PreparationDays uint `json:"preparationDays"`
+}
+
+type CheckInOutDTO struct {
+	CheckInTime  string   `json:"checkInTime"`
+	CheckOutTime string   `json:"checkOutTime"`
+	CheckInDays  []string `json:"checkInDays"`
+	TimeZone     string   `json:"timeZone"`
+}
+
 type SearchAccomodationDTO struct {
 	Address        string    `json:"address"`
 	NumberOfGuests uint      `json:"numberOfGuests"`
 	StartDate      time.Time `json:"startDate"`
 	EndDate        time.Time `json:"endDate"`
+	Currency       string    `json:"currency"`
 }
 
 type SearchAccomodationReturnDTO struct {
-	Accomodation   AccomodationDTO `json:"accomodation"`
-	NumberOfGuests uint            `json:"numberOfGuests"`
-	StartDate      time.Time       `json:"startDate"`
-	EndDate        time.Time       `json:"endDate"`
-	Price          float32         `json:"price"`
-	TotalPrice     int             `json:"totalPrice"`
+	Accomodation   AccomodationDTO   `json:"accomodation"`
+	NumberOfGuests uint              `json:"numberOfGuests"`
+	StartDate      time.Time         `json:"startDate"`
+	EndDate        time.Time         `json:"endDate"`
+	Price          Money             `json:"price"`
+	TotalPrice     Money             `json:"totalPrice"`
+	PriceBreakdown PriceBreakdownDTO `json:"priceBreakdown"`
+	DisplayPrice   *DisplayPriceDTO  `json:"displayPrice,omitempty"`
+}
+
+// DisplayPriceDTO repeats the prices of a stay in the currency the guest asked
+// for, next to the rate they were converted at.
+type DisplayPriceDTO struct {
+	Currency   string  `json:"currency"`
+	Rate       float64 `json:"rate"`
+	Price      Money   `json:"price"`
+	TotalPrice Money   `json:"totalPrice"`
+}
+
+type CalendarDayDTO struct {
+	Date          time.Time         `json:"date"`
+	Status        CalendarDayStatus `json:"status"`
+	Price         Money             `json:"price"`
+	PriceDuration PriceDuration     `json:"priceDuration"`
+}
+
+type CalendarDTO struct {
+	AccomodationID uint             `json:"accomodationId"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	Days           []CalendarDayDTO `json:"days"`
+}
+
+type CalendarTokenDTO struct {
+	Token string `json:"token"`
+}
+
+type CreateCalendarImportDTO struct {
+	Url string `json:"url"`
+}
+
+type CalendarImportDTO struct {
+	Id             uint       `json:"id"`
+	AccomodationID uint       `json:"accomodationId"`
+	Url            string     `json:"url"`
+	LastSyncedAt   *time.Time `json:"lastSyncedAt"`
+	LastError      string     `json:"lastError"`
+}
+
+type CreateAvailabilityRuleDTO struct {
+	Weekdays       []string  `json:"weekdays"`
+	Months         []int     `json:"months"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	ExceptionDates []string  `json:"exceptionDates"`
+	AccomodationID uint      `json:"accomodationId"`
+}
+
+type AvailabilityRuleDTO struct {
+	Id             uint      `json:"id"`
+	Weekdays       []string  `json:"weekdays"`
+	Months         []int     `json:"months"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	ExceptionDates []string  `json:"exceptionDates"`
+	AccomodationID uint      `json:"accomodationId"`
+}
+
+type CreateStayRuleDTO struct {
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	MinimumNights  uint      `json:"minimumNights"`
+	MaximumNights  uint      `json:"maximumNights"`
+	AccomodationID uint      `json:"accomodationId"`
+}
+
+type StayRuleDTO struct {
+	Id             uint      `json:"id"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	MinimumNights  uint      `json:"minimumNights"`
+	MaximumNights  uint      `json:"maximumNights"`
+	AccomodationID uint      `json:"accomodationId"`
+}
+
+type CreateDiscountRuleDTO struct {
+	Type              DiscountType `json:"type"`
+	MinimumNights     uint         `json:"minimumNights"`
+	DaysBeforeCheckIn uint         `json:"daysBeforeCheckIn"`
+	Percentage        float32      `json:"percentage"`
+	AccomodationID    uint         `json:"accomodationId"`
+}
+
+type DiscountRuleDTO struct {
+	Id                uint         `json:"id"`
+	Type              DiscountType `json:"type"`
+	MinimumNights     uint         `json:"minimumNights"`
+	DaysBeforeCheckIn uint         `json:"daysBeforeCheckIn"`
+	Percentage        float32      `json:"percentage"`
+	AccomodationID    uint         `json:"accomodationId"`
+}
+
+type AppliedDiscountDTO struct {
+	DiscountRuleId    uint         `json:"discountRuleId"`
+	Type              DiscountType `json:"type"`
+	MinimumNights     uint         `json:"minimumNights"`
+	DaysBeforeCheckIn uint         `json:"daysBeforeCheckIn"`
+	Percentage        float32      `json:"percentage"`
+	Amount            Money        `json:"amount"`
+}
+
+// PriceBreakdownDTO explains the price of a stay. BasePrice is the price of
+// the first night and NightsTotal the sum of the prices of all nights, each
+// priced as the calendar shows it.
+type PriceBreakdownDTO struct {
+	BasePrice        Money                `json:"basePrice"`
+	Nights           int                  `json:"nights"`
+	NightsTotal      Money                `json:"nightsTotal"`
+	ExtraGuests      uint                 `json:"extraGuests"`
+	ExtraGuestFee    Money                `json:"extraGuestFee"`
+	ExtraGuestsTotal Money                `json:"extraGuestsTotal"`
+	Subtotal         Money                `json:"subtotal"`
+	Discounts        []AppliedDiscountDTO `json:"discounts"`
+	Fees             []AppliedFeeDTO      `json:"fees"`
+	FeesTotal        Money                `json:"feesTotal"`
+	Taxes            []AppliedFeeDTO      `json:"taxes"`
+	TaxesTotal       Money                `json:"taxesTotal"`
+	Total            Money                `json:"total"`
+}
+
+type CreateFeeDTO struct {
+	Name           string   `json:"name"`
+	Kind           FeeKind  `json:"kind"`
+	Basis          FeeBasis `json:"basis"`
+	Amount         Money    `json:"amount"`
+	Percentage     float32  `json:"percentage"`
+	AccomodationID uint     `json:"accomodationId"`
+}
+
+type FeeDTO struct {
+	Id             uint     `json:"id"`
+	Name           string   `json:"name"`
+	Kind           FeeKind  `json:"kind"`
+	Basis          FeeBasis `json:"basis"`
+	Amount         Money    `json:"amount"`
+	Percentage     float32  `json:"percentage"`
+	AccomodationID uint     `json:"accomodationId"`
+}
+
+type AppliedFeeDTO struct {
+	FeeId      uint     `json:"feeId"`
+	Name       string   `json:"name"`
+	Basis      FeeBasis `json:"basis"`
+	Amount     Money    `json:"amount"`
+	Percentage float32  `json:"percentage"`
+	Total      Money    `json:"total"`
 }
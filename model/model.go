@@ -1,6 +1,9 @@
 package model
 
 import (
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -21,6 +24,15 @@ type Accomodation struct {
 	Prices                []Price
 	PriceType             PriceType
 	AcceptReservationType AcceptReservationType
+	CalendarToken         string `json:"-"`
+	CheckInTime           string
+	CheckOutTime          string
+	CheckInDays           string
+	PreparationDays       uint
+	TimeZone              string
+	BaseOccupancy         uint
+	ExtraGuestFee         Money `gorm:"embedded;embedded_prefix:extra_guest_fee_"`
+	Currency              string
 }
 
 type PriceType string
@@ -28,6 +40,9 @@ type PriceType string
 const (
 	PER_GUEST             PriceType = "PER GUEST"
 	PER_ACCOMODATION_UNIT PriceType = "PER ACCOMODATION UNIT"
+	// PER_OCCUPANCY charges the price per night for up to BaseOccupancy
+	// guests and ExtraGuestFee per night for every guest above that.
+	PER_OCCUPANCY PriceType = "PER OCCUPANCY"
 )
 
 type PriceDuration string
@@ -38,14 +53,85 @@ const (
 	HOLIDAY PriceDuration = "HOLIDAY"
 )
 
+// DateLayout is the format of calendar dates exchanged with clients.
+const DateLayout = "2006-01-02"
+
+// ClockLayout is the format of check-in and check-out times.
+const ClockLayout = "15:04"
+
+// Check-in and check-out times used when the host has not set their own.
+const (
+	DefaultCheckInTime  = "14:00"
+	DefaultCheckOutTime = "10:00"
+)
+
+// DefaultTimeZone is the IANA time zone of accomodations that do not set one.
+const DefaultTimeZone = "Europe/Belgrade"
+
+var locations sync.Map
+
+// LoadLocation returns the IANA time zone with the given name, remembering
+// zones that were already loaded.
+func LoadLocation(name string) (*time.Location, error) {
+	if location, found := locations.Load(name); found {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// CalendarDate drops the time of day and the zone of t, keeping only its
+// calendar date as midnight UTC. Terms, prices and rules are stored and
+// compared as calendar dates.
+func CalendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Weekdays maps weekdays to their RRULE BYDAY codes.
+var Weekdays = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+type CalendarDayStatus string
+
+const (
+	AVAILABLE CalendarDayStatus = "AVAILABLE"
+	RESERVED  CalendarDayStatus = "RESERVED"
+	BLOCKED   CalendarDayStatus = "BLOCKED"
+)
+
 type Price struct {
 	gorm.Model
 	StartDate      time.Time
 	EndDate        time.Time
-	Value          float32
+	Value          Money `gorm:"embedded;embedded_prefix:value_"`
 	PriceDuration  PriceDuration
 	AccomodationID uint
 	Active         bool
+	// PreviousPriceID points to the version this price replaced, so that
+	// the versions of a price form a chain.
+	PreviousPriceID uint
+	DeactivatedAt   *time.Time
+}
+
+// ActiveAt reports whether this version of the price was in effect at the
+// given moment.
+func (price *Price) ActiveAt(moment time.Time) bool {
+	if price.CreatedAt.After(moment) {
+		return false
+	}
+	return price.DeactivatedAt == nil || price.DeactivatedAt.After(moment)
 }
 
 type ReservedTerm struct {
@@ -62,6 +148,109 @@ type AvailableTerm struct {
 	AccomodationID uint
 }
 
+// AvailabilityRule makes an accomodation available on recurring days, the way
+// an RRULE does. Weekdays holds BYDAY codes ("SA,SU") and Months holds BYMONTH
+// numbers ("6,7,8"); an empty list matches every day or month. Zero start or
+// end dates leave the rule unbounded and ExceptionDates lists days
+// ("2006-01-02") on which the rule does not apply.
+type AvailabilityRule struct {
+	gorm.Model
+	AccomodationID uint
+	Weekdays       string
+	Months         string
+	StartDate      time.Time
+	EndDate        time.Time
+	ExceptionDates string
+}
+
+// StayRule limits how many nights a stay can last. Like Price, a rule with
+// dates applies to stays starting within them, while a rule without dates is
+// the default for the accomodation. Zero nights mean there is no limit.
+type StayRule struct {
+	gorm.Model
+	AccomodationID uint
+	StartDate      time.Time
+	EndDate        time.Time
+	MinimumNights  uint
+	MaximumNights  uint
+}
+
+type DiscountType string
+
+const (
+	// LENGTH_OF_STAY discounts stays lasting at least MinimumNights.
+	LENGTH_OF_STAY DiscountType = "LENGTH OF STAY"
+	// LAST_MINUTE discounts stays booked DaysBeforeCheckIn days or less
+	// before check-in.
+	LAST_MINUTE DiscountType = "LAST MINUTE"
+	// EARLY_BIRD discounts stays booked more than DaysBeforeCheckIn days
+	// before check-in.
+	EARLY_BIRD DiscountType = "EARLY BIRD"
+)
+
+// DiscountRule lowers the price of a stay by Percentage percent when the stay
+// meets the condition of its type. When several rules of the same type apply
+// only the largest of them is given, while discounts of different types add
+// up.
+type DiscountRule struct {
+	gorm.Model
+	AccomodationID    uint
+	Type              DiscountType
+	MinimumNights     uint
+	DaysBeforeCheckIn uint
+	Percentage        float32
+}
+
+type FeeKind string
+
+const (
+	FEE FeeKind = "FEE"
+	TAX FeeKind = "TAX"
+)
+
+type FeeBasis string
+
+const (
+	PER_STAY        FeeBasis = "PER STAY"
+	PER_NIGHT       FeeBasis = "PER NIGHT"
+	PER_GUEST_NIGHT FeeBasis = "PER GUEST NIGHT"
+	// PERCENTAGE charges Amount percent of the price of the stay after
+	// discounts.
+	PERCENTAGE FeeBasis = "PERCENTAGE"
+)
+
+// Fee is a charge added on top of the price of a stay, such as a cleaning fee
+// or a tourist tax. Kind decides whether it is listed among fees or taxes.
+// PERCENTAGE fees use Percentage, all other bases charge Amount.
+type Fee struct {
+	gorm.Model
+	AccomodationID uint
+	Name           string
+	Kind           FeeKind
+	Basis          FeeBasis
+	Amount         Money `gorm:"embedded;embedded_prefix:charge_"`
+	Percentage     float32
+}
+
+// CalendarImport is an external calendar whose busy periods block the
+// accomodation. Imports without an Url come from uploaded files.
+type CalendarImport struct {
+	gorm.Model
+	AccomodationID uint
+	Url            string
+	LastSyncedAt   *time.Time
+	LastError      string
+}
+
+type BlockedTerm struct {
+	gorm.Model
+	StartDate        time.Time
+	EndDate          time.Time
+	AccomodationID   uint
+	CalendarImportID uint
+	UID              string
+}
+
 func (accomodation *Accomodation) ToDTO() AccomodationDTO {
 	return AccomodationDTO{Id: accomodation.ID,
 		Name:                  accomodation.Name,
@@ -75,8 +264,84 @@ func (accomodation *Accomodation) ToDTO() AccomodationDTO {
 		Images:                []string{},
 		UserId:                accomodation.UserId,
 		AcceptReservationType: accomodation.AcceptReservationType,
-		PriceType:             accomodation.PriceType}
+		PriceType:             accomodation.PriceType,
+		CheckInTime:           accomodation.checkInTime(),
+		CheckOutTime:          accomodation.checkOutTime(),
+		CheckInDays:           splitList(accomodation.CheckInDays),
+		PreparationDays:       accomodation.PreparationDays,
+		TimeZone:              accomodation.timeZone(),
+		BaseOccupancy:         accomodation.BaseOccupancy,
+		ExtraGuestFee:         accomodation.ExtraGuestFee,
+		Currency:              accomodation.CurrencyCode()}
+
+}
+
+// Location returns the time zone of the accomodation, falling back to UTC when
+// the stored zone can not be loaded.
+func (accomodation *Accomodation) Location() *time.Location {
+	location, err := LoadLocation(accomodation.timeZone())
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// DateOf returns the calendar date on which the given moment falls at the
+// accomodation.
+func (accomodation *Accomodation) DateOf(moment time.Time) time.Time {
+	return CalendarDate(moment.In(accomodation.Location()))
+}
+
+// CheckIn returns the moment a stay starting on the given calendar date
+// begins, in the time zone of the accomodation.
+func (accomodation *Accomodation) CheckIn(day time.Time) time.Time {
+	return atClock(day, accomodation.checkInTime(), accomodation.Location())
+}
+
+// CheckOut returns the moment a stay ending on the given calendar date ends,
+// in the time zone of the accomodation.
+func (accomodation *Accomodation) CheckOut(day time.Time) time.Time {
+	return atClock(day, accomodation.checkOutTime(), accomodation.Location())
+}
+
+// AllowsCheckIn reports whether guests can arrive on the given day. An empty
+// CheckInDays list allows arrivals on every day of the week.
+func (accomodation *Accomodation) AllowsCheckIn(day time.Time) bool {
+	return accomodation.CheckInDays == "" || containsListItem(accomodation.CheckInDays, Weekdays[day.Weekday()])
+}
+
+func (accomodation *Accomodation) checkInTime() string {
+	if accomodation.CheckInTime == "" {
+		return DefaultCheckInTime
+	}
+	return accomodation.CheckInTime
+}
+
+func (accomodation *Accomodation) checkOutTime() string {
+	if accomodation.CheckOutTime == "" {
+		return DefaultCheckOutTime
+	}
+	return accomodation.CheckOutTime
+}
 
+// CurrencyCode returns the currency the accomodation is priced in.
+func (accomodation *Accomodation) CurrencyCode() string {
+	if accomodation.Currency == "" {
+		return DefaultCurrency
+	}
+	return accomodation.Currency
+}
+
+func (accomodation *Accomodation) timeZone() string {
+	if accomodation.TimeZone == "" {
+		return DefaultTimeZone
+	}
+	return accomodation.TimeZone
+}
+
+func atClock(day time.Time, clock string, location *time.Location) time.Time {
+	parsed, _ := time.Parse(ClockLayout, clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, location)
 }
 
 type AccomodationImage struct {
@@ -94,11 +359,15 @@ const (
 
 func (price *Price) ToDTO() PriceDTO {
 	return PriceDTO{Id: price.ID,
-		StartDate:      price.StartDate,
-		EndDate:        price.EndDate,
-		Value:          price.Value,
-		PriceDuration:  price.PriceDuration,
-		AccomodationID: price.AccomodationID}
+		StartDate:       price.StartDate,
+		EndDate:         price.EndDate,
+		Value:           price.Value,
+		PriceDuration:   price.PriceDuration,
+		AccomodationID:  price.AccomodationID,
+		Active:          price.Active,
+		PreviousPriceId: price.PreviousPriceID,
+		ActiveFrom:      price.CreatedAt,
+		ActiveTo:        price.DeactivatedAt}
 }
 
 func (availableTerm *AvailableTerm) ToDTO() AvailableTermDTO {
@@ -114,3 +383,107 @@ func (reservedTerm *ReservedTerm) ToDTO() ReservedTermDTO {
 		EndDate:        reservedTerm.EndDate,
 		AccomodationID: reservedTerm.AccomodationID}
 }
+
+func (calendarImport *CalendarImport) ToDTO() CalendarImportDTO {
+	return CalendarImportDTO{Id: calendarImport.ID,
+		AccomodationID: calendarImport.AccomodationID,
+		Url:            calendarImport.Url,
+		LastSyncedAt:   calendarImport.LastSyncedAt,
+		LastError:      calendarImport.LastError}
+}
+
+func (availabilityRule *AvailabilityRule) ToDTO() AvailabilityRuleDTO {
+	availabilityRuleDTO := AvailabilityRuleDTO{Id: availabilityRule.ID,
+		Weekdays:       splitList(availabilityRule.Weekdays),
+		Months:         []int{},
+		StartDate:      availabilityRule.StartDate,
+		EndDate:        availabilityRule.EndDate,
+		ExceptionDates: splitList(availabilityRule.ExceptionDates),
+		AccomodationID: availabilityRule.AccomodationID}
+
+	for _, month := range splitList(availabilityRule.Months) {
+		number, _ := strconv.Atoi(month)
+		availabilityRuleDTO.Months = append(availabilityRuleDTO.Months, number)
+	}
+	return availabilityRuleDTO
+}
+
+// Allows reports whether the rule makes the accomodation available on the
+// calendar date of the given day.
+func (availabilityRule *AvailabilityRule) Allows(day time.Time) bool {
+	date := day.Format(DateLayout)
+
+	if !availabilityRule.StartDate.IsZero() && date < availabilityRule.StartDate.Format(DateLayout) {
+		return false
+	}
+	if !availabilityRule.EndDate.IsZero() && date > availabilityRule.EndDate.Format(DateLayout) {
+		return false
+	}
+	if availabilityRule.Weekdays != "" && !containsListItem(availabilityRule.Weekdays, Weekdays[day.Weekday()]) {
+		return false
+	}
+	if availabilityRule.Months != "" && !containsListItem(availabilityRule.Months, strconv.Itoa(int(day.Month()))) {
+		return false
+	}
+	return !containsListItem(availabilityRule.ExceptionDates, date)
+}
+
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsListItem(list string, item string) bool {
+	for _, listItem := range splitList(list) {
+		if listItem == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies reports whether a stay of the given number of nights, booked the
+// given number of days before check-in, gets the discount.
+func (discountRule *DiscountRule) Applies(nights int, daysBeforeCheckIn int) bool {
+	switch discountRule.Type {
+	case LAST_MINUTE:
+		return daysBeforeCheckIn <= int(discountRule.DaysBeforeCheckIn) && nights >= int(discountRule.MinimumNights)
+	case EARLY_BIRD:
+		return daysBeforeCheckIn > int(discountRule.DaysBeforeCheckIn) && nights >= int(discountRule.MinimumNights)
+	default:
+		return nights >= int(discountRule.MinimumNights)
+	}
+}
+
+func (fee *Fee) ToDTO() FeeDTO {
+	return FeeDTO{Id: fee.ID,
+		Name:           fee.Name,
+		Kind:           fee.Kind,
+		Basis:          fee.Basis,
+		Amount:         fee.Amount,
+		Percentage:     fee.Percentage,
+		AccomodationID: fee.AccomodationID}
+}
+
+func (discountRule *DiscountRule) ToDTO() DiscountRuleDTO {
+	return DiscountRuleDTO{Id: discountRule.ID,
+		Type:              discountRule.Type,
+		MinimumNights:     discountRule.MinimumNights,
+		DaysBeforeCheckIn: discountRule.DaysBeforeCheckIn,
+		Percentage:        discountRule.Percentage,
+		AccomodationID:    discountRule.AccomodationID}
+}
+
+func (stayRule *StayRule) ToDTO() StayRuleDTO {
+	return StayRuleDTO{Id: stayRule.ID,
+		StartDate:      stayRule.StartDate,
+		EndDate:        stayRule.EndDate,
+		MinimumNights:  stayRule.MinimumNights,
+		MaximumNights:  stayRule.MaximumNights,
+		AccomodationID: stayRule.AccomodationID}
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of accomodations that do not set their own
+// and of amounts sent without a currency.
+const DefaultCurrency = "RSD"
+
+// currencyExponents holds the number of minor unit digits of the supported
+// ISO 4217 currencies.
+var currencyExponents = map[string]int{
+	"BAM": 2,
+	"CHF": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HUF": 2,
+	"JPY": 0,
+	"MKD": 2,
+	"RSD": 2,
+	"USD": 2,
+}
+
+// Money is an amount in the minor units of its currency, such as cents for
+// EUR or para for RSD. Amounts are kept as integers so that totals add up
+// exactly; only percentages are rounded, half away from zero, to whole minor
+// units.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// IsCurrency reports whether the currency is a supported ISO 4217 code.
+func IsCurrency(currency string) bool {
+	_, found := currencyExponents[currency]
+	return found
+}
+
+// CurrencyExponent returns the number of minor unit digits of a supported
+// currency, such as 2 for EUR.
+func CurrencyExponent(currency string) int {
+	return currencyExponents[currency]
+}
+
+// NewMoney returns an amount given in minor units.
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney reads a decimal amount such as "30.50" in the given currency. It
+// rejects more decimal digits than the currency has minor units.
+func ParseMoney(amount string, currency string) (Money, error) {
+	exponent, found := currencyExponents[currency]
+	if !found {
+		return Money{}, errors.New("currency " + currency + " is not supported")
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if len(fraction) > exponent {
+		return Money{}, errors.New("amount " + amount + " has more decimals than " + currency + " allows")
+	}
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	if whole == "" || strings.ContainsAny(digits, "+-") {
+		return Money{}, errors.New("amount " + amount + " is not a decimal number")
+	}
+
+	minorUnits, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, errors.New("amount " + amount + " is not a decimal number")
+	}
+	if negative {
+		minorUnits = -minorUnits
+	}
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// MoneyFromFloat converts a legacy floating point amount in major units,
+// rounding half away from zero to whole minor units.
+func MoneyFromFloat(amount float64, currency string) Money {
+	return Money{Amount: int64(math.Round(amount * math.Pow10(currencyExponents[currency]))), Currency: currency}
+}
+
+// Decimal formats the amount in major units, for example "30.50".
+func (money Money) Decimal() string {
+	exponent := currencyExponents[money.Currency]
+	amount := money.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (money Money) String() string {
+	return money.Decimal() + " " + money.Currency
+}
+
+// Add returns the sum of two amounts in the same currency. A zero Money
+// without a currency takes the currency of the other amount.
+func (money Money) Add(other Money) Money {
+	return Money{Amount: money.Amount + other.Amount, Currency: money.currencyWith(other)}
+}
+
+// Sub returns the difference of two amounts in the same currency.
+func (money Money) Sub(other Money) Money {
+	return Money{Amount: money.Amount - other.Amount, Currency: money.currencyWith(other)}
+}
+
+// Times multiplies the amount by a whole number, such as a number of nights.
+func (money Money) Times(multiplier int64) Money {
+	return Money{Amount: money.Amount * multiplier, Currency: money.Currency}
+}
+
+// Percent returns the given percentage of the amount. The percentage is used
+// with two decimals and the result is rounded half away from zero.
+func (money Money) Percent(percentage float64) Money {
+	basisPoints := int64(math.Round(percentage * 100))
+	return Money{Amount: divideRounded(money.Amount*basisPoints, 10000), Currency: money.Currency}
+}
+
+// Convert returns the amount in another currency at the given rate, which is
+// the price of one major unit of the amount's currency in the other currency.
+// The result is rounded half away from zero to whole minor units.
+func (money Money) Convert(rate float64, currency string) Money {
+	scale := math.Pow10(currencyExponents[currency] - currencyExponents[money.Currency])
+	return Money{Amount: int64(math.Round(float64(money.Amount) * rate * scale)), Currency: currency}
+}
+
+func (money Money) IsNegative() bool {
+	return money.Amount < 0
+}
+
+func (money Money) currencyWith(other Money) string {
+	if money.Currency == "" {
+		return other.Currency
+	}
+	return money.Currency
+}
+
+// divideRounded divides by a positive denominator, rounding half away from
+// zero.
+func divideRounded(numerator int64, denominator int64) int64 {
+	quotient, remainder := numerator/denominator, numerator%denominator
+	if remainder*2 >= denominator {
+		quotient++
+	} else if remainder*2 <= -denominator {
+		quotient--
+	}
+	return quotient
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes the amount as a decimal string so that clients never
+// see floating point values, for example {"amount":"30.50","currency":"RSD"}.
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: money.Decimal(), Currency: money.Currency})
+}
+
+// UnmarshalJSON reads an object with a decimal amount given as a string or a
+// number. A bare number, as sent by older clients, is an amount in
+// DefaultCurrency.
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil {
+		parsed, err := ParseMoney(number.String(), DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*money = parsed
+		return nil
+	}
+
+	var object struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	if object.Amount == "" {
+		object.Amount = "0"
+	}
+	if object.Currency == "" {
+		object.Currency = DefaultCurrency
+	}
+
+	parsed, err := ParseMoney(object.Amount.String(), object.Currency)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
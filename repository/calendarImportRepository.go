@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (r *Repository) SaveCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "saveCalendarImportRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&calendarImport)
+	return calendarImport
+}
+
+func (r *Repository) UpdateCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "updateCalendarImportRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Save(&calendarImport)
+	return calendarImport
+}
+
+func (r *Repository) FindCalendarImportById(id uint64, ctx context.Context) (model.CalendarImport, error) {
+	span := tracer.StartSpanFromContext(ctx, "findCalendarImportByIdRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	var calendarImport model.CalendarImport
+
+	r.db(ctx).First(&calendarImport, id)
+
+	if calendarImport.ID == 0 {
+		err := errors.New("there is no calendar import with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return model.CalendarImport{}, err
+	}
+
+	return calendarImport, nil
+}
+
+func (r *Repository) FindCalendarImportsForAccomodation(accomodationId uint, ctx context.Context) []model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "findCalendarImportsForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	calendarImports := &[]model.CalendarImport{}
+
+	r.db(ctx).Find(&calendarImports, "accomodation_id = ?", accomodationId)
+	return *calendarImports
+}
+
+func (r *Repository) FindUrlCalendarImports(ctx context.Context) []model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "findUrlCalendarImportsRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	calendarImports := &[]model.CalendarImport{}
+
+	r.db(ctx).Find(&calendarImports, "url <> ''")
+	return *calendarImports
+}
+
+func (r *Repository) DeleteCalendarImport(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteCalendarImportRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Where("calendar_import_id = ?", id).Delete(&model.BlockedTerm{}).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	result := r.db(ctx).Delete(&model.CalendarImport{}, id)
+	if result.Error != nil {
+		tracer.LogError(span, result.Error)
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		err := errors.New("there is no calendar import with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) SaveBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+	span := tracer.StartSpanFromContext(ctx, "saveBlockedTermRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&blockedTerm)
+	return blockedTerm
+}
+
+func (r *Repository) UpdateBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+	span := tracer.StartSpanFromContext(ctx, "updateBlockedTermRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Save(&blockedTerm)
+	return blockedTerm
+}
+
+func (r *Repository) DeleteBlockedTerm(id uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteBlockedTermRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Delete(&model.BlockedTerm{}, id).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) FindBlockedTermsForCalendarImport(calendarImportId uint, ctx context.Context) []model.BlockedTerm {
+	span := tracer.StartSpanFromContext(ctx, "findBlockedTermsForCalendarImportRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	blockedTerms := &[]model.BlockedTerm{}
+
+	r.db(ctx).Find(&blockedTerms, "calendar_import_id = ?", calendarImportId)
+	return *blockedTerms
+}
+
+func (r *Repository) FindBlockedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+	span := tracer.StartSpanFromContext(ctx, "findBlockedTermsBetweenRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	blockedTerms := &[]model.BlockedTerm{}
+
+	r.db(ctx).Find(&blockedTerms, "accomodation_id = ? AND start_date <= ? AND end_date >= ?", accomodationId, endDate, startDate)
+	return *blockedTerms
+}
+
+// IsBlocked treats end dates as exclusive, the way iCalendar does, so an
+// external checkout and a check-in on the same day do not collide.
+func (r *Repository) IsBlocked(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "isBlockedRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	count := int64(0)
+
+	r.db(ctx).Model(&model.BlockedTerm{}).Where("accomodation_id = ? AND start_date < ? AND end_date > ?", accomodationId, endDate, startDate).Count(&count)
+
+	return count > 0
+}
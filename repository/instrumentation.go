@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/windbnb/accomodation-service/metrics"
+	"github.com/windbnb/accomodation-service/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	contextKey   = "instrumentation:context"
+	startedAtKey = "instrumentation:started_at"
+	spanKey      = "instrumentation:span"
+
+	rowsAffectedKey = attribute.Key("db.rows_affected")
+)
+
+// db returns the connection to query with on behalf of ctx, so that the
+// spans of the queries join the trace in ctx.
+func (r *Repository) db(ctx context.Context) *gorm.DB {
+	return r.Db.Set(contextKey, ctx)
+}
+
+// Instrument registers gorm callbacks that time every query into the
+// database_query_duration_seconds histogram and, for queries made within a
+// trace, record a span with the statement, the rows affected and the error.
+func Instrument(db *gorm.DB) {
+	callbacks := db.Callback()
+
+	callbacks.Create().Before("gorm:begin_transaction").Register("instrumentation:before_create", beforeQuery("create"))
+	callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("instrumentation:after_create", afterQuery("create"))
+	callbacks.Query().Before("gorm:query").Register("instrumentation:before_query", beforeQuery("query"))
+	callbacks.Query().After("gorm:after_query").Register("instrumentation:after_query", afterQuery("query"))
+	callbacks.RowQuery().Before("gorm:row_query").Register("instrumentation:before_row_query", beforeQuery("row_query"))
+	callbacks.RowQuery().After("gorm:row_query").Register("instrumentation:after_row_query", afterQuery("row_query"))
+	callbacks.Update().Before("gorm:begin_transaction").Register("instrumentation:before_update", beforeQuery("update"))
+	callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("instrumentation:after_update", afterQuery("update"))
+	callbacks.Delete().Before("gorm:begin_transaction").Register("instrumentation:before_delete", beforeQuery("delete"))
+	callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register("instrumentation:after_delete", afterQuery("delete"))
+}
+
+func beforeQuery(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		scope.InstanceSet(startedAtKey, time.Now())
+
+		ctx, found := scope.Get(contextKey)
+		if !found || !trace.SpanContextFromContext(ctx.(context.Context)).IsValid() {
+			return
+		}
+		span := tracer.StartSpanFromContext(ctx.(context.Context), operation+" "+scope.TableName())
+		span.SetAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBSQLTableKey.String(scope.TableName()))
+		scope.InstanceSet(spanKey, span)
+	}
+}
+
+func afterQuery(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		err := scope.DB().Error
+		// Not finding a record is an answer, not a failure of the query.
+		failed := err != nil && !gorm.IsRecordNotFoundError(err)
+
+		if startedAt, found := scope.InstanceGet(startedAtKey); found {
+			metrics.ObserveDatabaseQuery(operation, scope.TableName(), time.Since(startedAt.(time.Time)), failed)
+		}
+
+		value, found := scope.InstanceGet(spanKey)
+		if !found {
+			return
+		}
+		span := value.(tracer.Span)
+		defer span.Finish()
+		// The statement keeps its placeholders; the values bound to them are
+		// left out as they may hold personal data.
+		span.SetAttributes(semconv.DBStatementKey.String(scope.SQL))
+		if operation != "row_query" {
+			span.SetAttributes(rowsAffectedKey.Int64(scope.DB().RowsAffected))
+		}
+		if failed {
+			tracer.LogError(span, err)
+		}
+	}
+}
@@ -14,9 +14,9 @@ import (
 
 type IRepository interface {
 	SaveAccomodation(accomodation model.Accomodation, ctx context.Context) model.Accomodation
-	SaveAccomodationImage(image model.AccomodationImage) model.AccomodationImage
+	SaveAccomodationImage(image model.AccomodationImage, ctx context.Context) model.AccomodationImage
 	DeleteHostAccomodation(hostId uint, ctx context.Context) error
-	SavePrice(price model.Price) model.Price
+	SavePrice(price model.Price, ctx context.Context) model.Price
 	SaveAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm
 	SaveReservedTerm(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm
 	UpdatePrice(price model.Price, ctx context.Context) model.Price
@@ -28,16 +28,49 @@ type IRepository interface {
 	FindAvailableTermAfter(accommodationId uint, after time.Time, ctx context.Context) []model.AvailableTerm
 	FindReservedTermById(id uint64, ctx context.Context) (model.ReservedTerm, error)
 	DeletePrice(id uint64, ctx context.Context) error
-	DeleteAvailableTerm(id uint64) error
-	DeleteReservedTerm(id uint64) error
+	DeleteAvailableTerm(id uint64, ctx context.Context) error
+	DeleteReservedTerm(id uint64, ctx context.Context) error
 	FindAccomodationByGuestsAndAddress(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation
 	IsReserved(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
 	IsAvailable(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
-	FindPricesForAccomodation(accomodationId uint, startDate time.Time, endDate time.Time) []model.Price
-	FindImagesForAccomodation(accomodationId uint) []string
+	FindPricesForAccomodation(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price
+	FindImagesForAccomodation(accomodationId uint, ctx context.Context) []string
 	FindAccomodationsForHost(hostId uint, ctx context.Context) []model.Accomodation
 	GetAvailableTermsForAccomodation(accomodationId uint, ctx context.Context) []model.AvailableTerm
 	GetPricesForAccomodation(accomodationId uint, ctx context.Context) []model.Price
+	GetPriceHistoryForAccomodation(accomodationId uint, ctx context.Context) []model.Price
+	SavePriceVersion(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error)
+	FindAvailableTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm
+	FindReservedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm
+	GetReservedTermsForAccomodation(accomodationId uint, ctx context.Context) []model.ReservedTerm
+	SaveCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport
+	UpdateCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport
+	FindCalendarImportById(id uint64, ctx context.Context) (model.CalendarImport, error)
+	FindCalendarImportsForAccomodation(accomodationId uint, ctx context.Context) []model.CalendarImport
+	FindUrlCalendarImports(ctx context.Context) []model.CalendarImport
+	DeleteCalendarImport(id uint64, ctx context.Context) error
+	SaveBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm
+	UpdateBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm
+	DeleteBlockedTerm(id uint, ctx context.Context) error
+	FindBlockedTermsForCalendarImport(calendarImportId uint, ctx context.Context) []model.BlockedTerm
+	FindBlockedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm
+	IsBlocked(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
+	SaveAvailabilityRule(availabilityRule model.AvailabilityRule, ctx context.Context) model.AvailabilityRule
+	FindAvailabilityRuleById(id uint64, ctx context.Context) (model.AvailabilityRule, error)
+	FindAvailabilityRulesForAccomodation(accomodationId uint, ctx context.Context) []model.AvailabilityRule
+	DeleteAvailabilityRule(id uint64, ctx context.Context) error
+	SaveStayRule(stayRule model.StayRule, ctx context.Context) model.StayRule
+	FindStayRuleById(id uint64, ctx context.Context) (model.StayRule, error)
+	FindStayRulesForAccomodation(accomodationId uint, ctx context.Context) []model.StayRule
+	DeleteStayRule(id uint64, ctx context.Context) error
+	SaveDiscountRule(discountRule model.DiscountRule, ctx context.Context) model.DiscountRule
+	FindDiscountRuleById(id uint64, ctx context.Context) (model.DiscountRule, error)
+	FindDiscountRulesForAccomodation(accomodationId uint, ctx context.Context) []model.DiscountRule
+	DeleteDiscountRule(id uint64, ctx context.Context) error
+	SaveFee(fee model.Fee, ctx context.Context) model.Fee
+	FindFeeById(id uint64, ctx context.Context) (model.Fee, error)
+	FindFeesForAccomodation(accomodationId uint, ctx context.Context) []model.Fee
+	DeleteFee(id uint64, ctx context.Context) error
 }
 
 type Repository struct {
@@ -47,25 +80,31 @@ type Repository struct {
 func (r *Repository) SaveAccomodation(accomodation model.Accomodation, ctx context.Context) model.Accomodation {
 	span := tracer.StartSpanFromContext(ctx, "saveAccomodationRepository")
 	defer span.Finish()
-	r.Db.Create(&accomodation)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	r.db(ctx).Create(&accomodation)
 	return accomodation
 }
 
-func (r *Repository) SaveAccomodationImage(image model.AccomodationImage) model.AccomodationImage {
-	r.Db.Create(&image)
+func (r *Repository) SaveAccomodationImage(image model.AccomodationImage, ctx context.Context) model.AccomodationImage {
+	span := tracer.StartSpanFromContext(ctx, "saveAccomodationImageRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&image)
 	return image
 }
 
 func (r *Repository) DeleteHostAccomodation(hostId uint, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "saveAccomodationRepository")
 	defer span.Finish()
-	accomodationIdsSubQuery := r.Db.Table("accomodations").Where("user_id = ?", hostId).Select("id").SubQuery()
-	if err := r.Db.Where("accomodation_id IN (?)", accomodationIdsSubQuery).Delete(&model.AccomodationImage{}).Error; err != nil {
+	ctx = tracer.ContextWithSpan(ctx, span)
+	accomodationIdsSubQuery := r.db(ctx).Table("accomodations").Where("user_id = ?", hostId).Select("id").SubQuery()
+	if err := r.db(ctx).Where("accomodation_id IN (?)", accomodationIdsSubQuery).Delete(&model.AccomodationImage{}).Error; err != nil {
 		tracer.LogError(span, err)
 		return err
 	}
 
-	result := r.Db.Where("user_id = ?", hostId).Delete(&model.Accomodation{})
+	result := r.db(ctx).Where("user_id = ?", hostId).Delete(&model.Accomodation{})
 	if result.Error != nil {
 		tracer.LogError(span, result.Error)
 		return result.Error
@@ -77,56 +116,66 @@ func (r *Repository) DeleteHostAccomodation(hostId uint, ctx context.Context) er
 	return nil
 }
 
-func (r *Repository) SavePrice(price model.Price) model.Price {
-	r.Db.Create(&price)
+func (r *Repository) SavePrice(price model.Price, ctx context.Context) model.Price {
+	span := tracer.StartSpanFromContext(ctx, "savePriceRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&price)
 	return price
 }
 
 func (r *Repository) SaveAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
 	span := tracer.StartSpanFromContext(ctx, "saveAvailableTermRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 
-	r.Db.Create(&availableTerm)
+	r.db(ctx).Create(&availableTerm)
 	return availableTerm
 }
 
 func (r *Repository) SaveReservedTerm(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
 	span := tracer.StartSpanFromContext(ctx, "saveReservedTermRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 
-	r.Db.Create(&reservedTerm)
+	r.db(ctx).Create(&reservedTerm)
 	return reservedTerm
 }
 
 func (r *Repository) UpdatePrice(price model.Price, ctx context.Context) model.Price {
 	span := tracer.StartSpanFromContext(ctx, "updatePriceRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 
-	r.Db.Save(&price)
+	r.db(ctx).Save(&price)
 	return price
 }
 
 func (r *Repository) UpdateAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
 	span := tracer.StartSpanFromContext(ctx, "updateAvailableTermRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 
-	r.Db.Save(&availableTerm)
+	r.db(ctx).Save(&availableTerm)
 	return availableTerm
 }
 
 func (r *Repository) UpdateAccommodation(accommodation model.Accomodation, ctx context.Context) model.Accomodation {
 	span := tracer.StartSpanFromContext(ctx, "updateAccomodationRepository")
 	defer span.Finish()
-	r.Db.Save(&accommodation)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	r.db(ctx).Save(&accommodation)
 	return accommodation
 }
 
 func (r *Repository) FindAccomodationById(id uint, ctx context.Context) (model.Accomodation, error) {
 	span := tracer.StartSpanFromContext(ctx, "findAccomodationByIdRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var accomodation model.Accomodation
 
-	r.Db.First(&accomodation, id)
+	r.db(ctx).First(&accomodation, id)
 
 	if accomodation.ID == 0 {
 		err := errors.New("there is no accomodation with id " + strconv.FormatUint(uint64(id), 10))
@@ -140,9 +189,10 @@ func (r *Repository) FindAccomodationById(id uint, ctx context.Context) (model.A
 func (r *Repository) FindPriceById(id uint64, ctx context.Context) (model.Price, error) {
 	span := tracer.StartSpanFromContext(ctx, "findPriceByIdRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var price model.Price
 
-	r.Db.First(&price, id)
+	r.db(ctx).First(&price, id)
 
 	if price.ID == 0 {
 		err := errors.New("there is no price with id " + strconv.FormatUint(uint64(id), 10))
@@ -156,9 +206,10 @@ func (r *Repository) FindPriceById(id uint64, ctx context.Context) (model.Price,
 func (r *Repository) FindAvailableTermById(id uint64, ctx context.Context) (model.AvailableTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "findAvailableTermByIdRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var availableTerm model.AvailableTerm
 
-	r.Db.First(&availableTerm, id)
+	r.db(ctx).First(&availableTerm, id)
 
 	if availableTerm.ID == 0 {
 		err := errors.New("there is no available term with id " + strconv.FormatUint(uint64(id), 10))
@@ -172,9 +223,10 @@ func (r *Repository) FindAvailableTermById(id uint64, ctx context.Context) (mode
 func (r *Repository) FindAvailableTermAfter(accommodationId uint, after time.Time, ctx context.Context) []model.AvailableTerm {
 	span := tracer.StartSpanFromContext(ctx, "findAvailableTermAfterRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	availableTerms := &[]model.AvailableTerm{}
 
-	r.Db.Where("accomodation_id = ? and (start_date <= ? or end_date <= ?)", accommodationId, after, after).Find(availableTerms)
+	r.db(ctx).Where("accomodation_id = ? and (start_date <= ? or end_date <= ?)", accommodationId, after, after).Find(availableTerms)
 
 	return *availableTerms
 }
@@ -182,9 +234,10 @@ func (r *Repository) FindAvailableTermAfter(accommodationId uint, after time.Tim
 func (r *Repository) FindReservedTermById(id uint64, ctx context.Context) (model.ReservedTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "findReservedTermByIdRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var reservedTerm model.ReservedTerm
 
-	r.Db.First(&reservedTerm, id)
+	r.db(ctx).First(&reservedTerm, id)
 
 	if reservedTerm.ID == 0 {
 		err := errors.New("there is no reserved term with id " + strconv.FormatUint(uint64(id), 10))
@@ -198,9 +251,10 @@ func (r *Repository) FindReservedTermById(id uint64, ctx context.Context) (model
 func (r *Repository) DeletePrice(id uint64, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deletePriceRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var price model.Price
 
-	r.Db.First(&price, id)
+	r.db(ctx).First(&price, id)
 
 	if price.ID == 0 {
 		err := errors.New("there is no price with id " + strconv.FormatUint(uint64(id), 10))
@@ -208,42 +262,53 @@ func (r *Repository) DeletePrice(id uint64, ctx context.Context) error {
 		return err
 	}
 
-	r.Db.Delete(&model.Price{}, id)
+	r.db(ctx).Delete(&model.Price{}, id)
 	return nil
 }
 
-func (r *Repository) DeleteAvailableTerm(id uint64) error {
+func (r *Repository) DeleteAvailableTerm(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteAvailableTermRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var availableTerm model.AvailableTerm
 
-	r.Db.First(&availableTerm, id)
+	r.db(ctx).First(&availableTerm, id)
 
 	if availableTerm.ID == 0 {
-		return errors.New("there is no available term with id " + strconv.FormatUint(uint64(id), 10))
+		err := errors.New("there is no available term with id " + strconv.FormatUint(uint64(id), 10))
+		tracer.LogError(span, err)
+		return err
 	}
 
-	r.Db.Delete(&model.AvailableTerm{}, id)
+	r.db(ctx).Delete(&model.AvailableTerm{}, id)
 	return nil
 }
 
-func (r *Repository) DeleteReservedTerm(id uint64) error {
+func (r *Repository) DeleteReservedTerm(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteReservedTermRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var reservedTerm model.ReservedTerm
 
-	r.Db.First(&reservedTerm, id)
+	r.db(ctx).First(&reservedTerm, id)
 
 	if reservedTerm.ID == 0 {
-		return errors.New("there is no reserved term with id " + strconv.FormatUint(uint64(id), 10))
+		err := errors.New("there is no reserved term with id " + strconv.FormatUint(uint64(id), 10))
+		tracer.LogError(span, err)
+		return err
 	}
 
-	r.Db.Delete(&model.ReservedTerm{}, id)
+	r.db(ctx).Delete(&model.ReservedTerm{}, id)
 	return nil
 }
 
 func (r *Repository) FindAccomodationByGuestsAndAddress(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
 	span := tracer.StartSpanFromContext(ctx, "findAccomodationByGuestsAndAddressRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	accomodations := &[]model.Accomodation{}
 
-	r.Db.Find(&accomodations, "LOWER(address) LIKE ? AND minimim_guests <= ? AND maximum_guests >= ?", "%"+strings.ToLower(address)+"%", numberOfGuests, numberOfGuests)
+	r.db(ctx).Find(&accomodations, "LOWER(address) LIKE ? AND minimim_guests <= ? AND maximum_guests >= ?", "%"+strings.ToLower(address)+"%", numberOfGuests, numberOfGuests)
 
 	return *accomodations
 }
@@ -251,9 +316,10 @@ func (r *Repository) FindAccomodationByGuestsAndAddress(numberOfGuests uint, add
 func (r *Repository) IsReserved(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "isReservedRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	count := int64(0)
 
-	r.Db.Model(&model.ReservedTerm{}).Where("accomodation_id = ? AND start_date <= ? AND end_date >= ?", accomodationId, endDate, startDate).Count(&count)
+	r.db(ctx).Model(&model.ReservedTerm{}).Where("accomodation_id = ? AND start_date < ? AND end_date > ?", accomodationId, endDate, startDate).Count(&count)
 
 	if count > 0 {
 		return true
@@ -264,9 +330,10 @@ func (r *Repository) IsReserved(accomodationId uint, startDate time.Time, endDat
 func (r *Repository) IsAvailable(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "isAvailableRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	count := int64(0)
 
-	r.Db.Model(&model.AvailableTerm{}).Where("accomodation_id = ? AND start_date <= ? AND end_date >= ?", accomodationId, endDate, startDate).Count(&count)
+	r.db(ctx).Model(&model.AvailableTerm{}).Where("accomodation_id = ? AND start_date <= ? AND end_date >= ?", accomodationId, endDate, startDate).Count(&count)
 
 	if count > 0 {
 		return true
@@ -274,18 +341,24 @@ func (r *Repository) IsAvailable(accomodationId uint, startDate time.Time, endDa
 	return false
 }
 
-func (r *Repository) FindPricesForAccomodation(accomodationId uint, startDate time.Time, endDate time.Time) []model.Price {
+func (r *Repository) FindPricesForAccomodation(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+	span := tracer.StartSpanFromContext(ctx, "findPricesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	prices := &[]model.Price{}
 
-	r.Db.Find(&prices, "accomodation_id = ? AND start_date <= ? AND end_date >= ? AND active = true", accomodationId, endDate, startDate)
+	r.db(ctx).Find(&prices, "accomodation_id = ? AND start_date <= ? AND end_date >= ? AND active = true", accomodationId, endDate, startDate)
 
 	return *prices
 }
 
-func (r *Repository) FindImagesForAccomodation(accomodationId uint) []string {
+func (r *Repository) FindImagesForAccomodation(accomodationId uint, ctx context.Context) []string {
+	span := tracer.StartSpanFromContext(ctx, "findImagesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	accomodationImages := &[]model.AccomodationImage{}
 
-	r.Db.Find(&accomodationImages, "accomodation_id = ?", accomodationId)
+	r.db(ctx).Find(&accomodationImages, "accomodation_id = ?", accomodationId)
 
 	var imageNames []string
 	for _, accomodationImage := range *accomodationImages {
@@ -297,27 +370,287 @@ func (r *Repository) FindImagesForAccomodation(accomodationId uint) []string {
 func (r *Repository) FindAccomodationsForHost(hostId uint, ctx context.Context) []model.Accomodation {
 	span := tracer.StartSpanFromContext(ctx, "findAccomodationsForHostRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	accomodations := &[]model.Accomodation{}
 
-	r.Db.Find(&accomodations, "user_id = ?", hostId)
+	r.db(ctx).Find(&accomodations, "user_id = ?", hostId)
 	return *accomodations
 }
 
 func (r *Repository) GetAvailableTermsForAccomodation(accomodationId uint, ctx context.Context) []model.AvailableTerm {
 	span := tracer.StartSpanFromContext(ctx, "getAvailableTermsForAccomodationRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	availableTerms := &[]model.AvailableTerm{}
 
-	r.Db.Find(&availableTerms, "accomodation_id = ?", accomodationId)
+	r.db(ctx).Find(&availableTerms, "accomodation_id = ?", accomodationId)
 	return *availableTerms
 }
 
 func (r *Repository) GetPricesForAccomodation(accomodationId uint, ctx context.Context) []model.Price {
 	span := tracer.StartSpanFromContext(ctx, "getPricesForAccomodationRepository")
 	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	prices := &[]model.Price{}
+
+	r.db(ctx).Find(&prices, "accomodation_id = ? AND active = true", accomodationId)
+	return *prices
+}
+
+func (r *Repository) GetPriceHistoryForAccomodation(accomodationId uint, ctx context.Context) []model.Price {
+	span := tracer.StartSpanFromContext(ctx, "getPriceHistoryForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
 	prices := &[]model.Price{}
 
-	r.Db.Find(&prices, "accomodation_id = ?", accomodationId)
+	r.db(ctx).Order("created_at, id").Find(&prices, "accomodation_id = ?", accomodationId)
 	return *prices
 }
 
+// SavePriceVersion stores the deactivated previous version of a price together
+// with the version replacing it, so that the price is never left without an
+// active version or with two of them.
+func (r *Repository) SavePriceVersion(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+	span := tracer.StartSpanFromContext(ctx, "savePriceVersionRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	tx := r.db(ctx).Begin()
+	if err := tx.Save(&previousPrice).Error; err != nil {
+		tx.Rollback()
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	if err := tx.Create(&price).Error; err != nil {
+		tx.Rollback()
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	return price, nil
+}
+
+func (r *Repository) FindAvailableTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+	span := tracer.StartSpanFromContext(ctx, "findAvailableTermsBetweenRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	availableTerms := &[]model.AvailableTerm{}
+
+	r.db(ctx).Find(&availableTerms, "accomodation_id = ? AND start_date <= ? AND end_date >= ?", accomodationId, endDate, startDate)
+	return *availableTerms
+}
+
+func (r *Repository) FindReservedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+	span := tracer.StartSpanFromContext(ctx, "findReservedTermsBetweenRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	reservedTerms := &[]model.ReservedTerm{}
+
+	r.db(ctx).Find(&reservedTerms, "accomodation_id = ? AND start_date < ? AND end_date > ?", accomodationId, endDate, startDate)
+	return *reservedTerms
+}
+
+func (r *Repository) GetReservedTermsForAccomodation(accomodationId uint, ctx context.Context) []model.ReservedTerm {
+	span := tracer.StartSpanFromContext(ctx, "getReservedTermsForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	reservedTerms := &[]model.ReservedTerm{}
+
+	r.db(ctx).Find(&reservedTerms, "accomodation_id = ?", accomodationId)
+	return *reservedTerms
+}
+
+func (r *Repository) SaveAvailabilityRule(availabilityRule model.AvailabilityRule, ctx context.Context) model.AvailabilityRule {
+	span := tracer.StartSpanFromContext(ctx, "saveAvailabilityRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&availabilityRule)
+	return availabilityRule
+}
+
+func (r *Repository) FindAvailabilityRuleById(id uint64, ctx context.Context) (model.AvailabilityRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "findAvailabilityRuleByIdRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	var availabilityRule model.AvailabilityRule
+
+	r.db(ctx).First(&availabilityRule, id)
+
+	if availabilityRule.ID == 0 {
+		err := errors.New("there is no availability rule with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return model.AvailabilityRule{}, err
+	}
+
+	return availabilityRule, nil
+}
+
+func (r *Repository) FindAvailabilityRulesForAccomodation(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+	span := tracer.StartSpanFromContext(ctx, "findAvailabilityRulesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	availabilityRules := &[]model.AvailabilityRule{}
+
+	r.db(ctx).Find(&availabilityRules, "accomodation_id = ?", accomodationId)
+	return *availabilityRules
+}
+
+func (r *Repository) DeleteAvailabilityRule(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteAvailabilityRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Delete(&model.AvailabilityRule{}, id).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) SaveStayRule(stayRule model.StayRule, ctx context.Context) model.StayRule {
+	span := tracer.StartSpanFromContext(ctx, "saveStayRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&stayRule)
+	return stayRule
+}
+
+func (r *Repository) FindStayRuleById(id uint64, ctx context.Context) (model.StayRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "findStayRuleByIdRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	var stayRule model.StayRule
+
+	r.db(ctx).First(&stayRule, id)
+
+	if stayRule.ID == 0 {
+		err := errors.New("there is no stay rule with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return model.StayRule{}, err
+	}
+
+	return stayRule, nil
+}
+
+func (r *Repository) FindStayRulesForAccomodation(accomodationId uint, ctx context.Context) []model.StayRule {
+	span := tracer.StartSpanFromContext(ctx, "findStayRulesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	stayRules := &[]model.StayRule{}
+
+	r.db(ctx).Find(&stayRules, "accomodation_id = ?", accomodationId)
+	return *stayRules
+}
+
+func (r *Repository) DeleteStayRule(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteStayRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Delete(&model.StayRule{}, id).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) SaveDiscountRule(discountRule model.DiscountRule, ctx context.Context) model.DiscountRule {
+	span := tracer.StartSpanFromContext(ctx, "saveDiscountRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&discountRule)
+	return discountRule
+}
+
+func (r *Repository) FindDiscountRuleById(id uint64, ctx context.Context) (model.DiscountRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "findDiscountRuleByIdRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	var discountRule model.DiscountRule
+
+	r.db(ctx).First(&discountRule, id)
+
+	if discountRule.ID == 0 {
+		err := errors.New("there is no discount rule with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return model.DiscountRule{}, err
+	}
+
+	return discountRule, nil
+}
+
+func (r *Repository) FindDiscountRulesForAccomodation(accomodationId uint, ctx context.Context) []model.DiscountRule {
+	span := tracer.StartSpanFromContext(ctx, "findDiscountRulesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	discountRules := &[]model.DiscountRule{}
+
+	r.db(ctx).Find(&discountRules, "accomodation_id = ?", accomodationId)
+	return *discountRules
+}
+
+func (r *Repository) DeleteDiscountRule(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteDiscountRuleRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Delete(&model.DiscountRule{}, id).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) SaveFee(fee model.Fee, ctx context.Context) model.Fee {
+	span := tracer.StartSpanFromContext(ctx, "saveFeeRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	r.db(ctx).Create(&fee)
+	return fee
+}
+
+func (r *Repository) FindFeeById(id uint64, ctx context.Context) (model.Fee, error) {
+	span := tracer.StartSpanFromContext(ctx, "findFeeByIdRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	var fee model.Fee
+
+	r.db(ctx).First(&fee, id)
+
+	if fee.ID == 0 {
+		err := errors.New("there is no fee with id " + strconv.FormatUint(id, 10))
+		tracer.LogError(span, err)
+		return model.Fee{}, err
+	}
+
+	return fee, nil
+}
+
+func (r *Repository) FindFeesForAccomodation(accomodationId uint, ctx context.Context) []model.Fee {
+	span := tracer.StartSpanFromContext(ctx, "findFeesForAccomodationRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	fees := &[]model.Fee{}
+
+	r.db(ctx).Find(&fees, "accomodation_id = ?", accomodationId)
+	return *fees
+}
+
+func (r *Repository) DeleteFee(id uint64, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteFeeRepository")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := r.db(ctx).Delete(&model.Fee{}, id).Error; err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+	return nil
+}
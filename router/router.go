@@ -2,34 +2,73 @@ package router
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/windbnb/accomodation-service/auth"
 	"github.com/windbnb/accomodation-service/handler"
 	"github.com/windbnb/accomodation-service/metrics"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
 )
 
+// ConfigureRouter registers the routes of the service. Routes wrapped in host
+// are only served to authenticated hosts, and routes wrapped in
+// windbnbService only to the other services of windbnb.
 func ConfigureRouter(handler *handler.Handler) *mux.Router {
+	host := auth.Require(handler.Authenticator, model.HOST)
+	windbnbService := auth.RequireService(service.ServiceTokenHeader, handler.Service.AuthorizeServiceCaller)
+
 	router := mux.NewRouter()
-	router.HandleFunc("/api/accomodation/create", metrics.MetricProxy(handler.CreateAccomodation)).Methods("POST")
+	router.HandleFunc("/api/accomodation/create", metrics.MetricProxy(host(handler.CreateAccomodation))).Methods("POST")
 	router.HandleFunc("/api/accomodation/{id}", metrics.MetricProxy(handler.FindAccommodationById)).Methods("GET")
-	router.HandleFunc("/api/accomodation/{id}/acceptReservationType", metrics.MetricProxy(handler.UpdateAccommodationAcceptReservationType)).Methods("PUT")
+	router.HandleFunc("/api/accomodation/{id}/calendar", metrics.MetricProxy(handler.GetCalendar)).Methods("GET")
+	router.HandleFunc("/api/accomodation/{id}/calendar.ics", metrics.MetricProxy(handler.ExportCalendar)).Methods("GET")
+	router.HandleFunc("/api/accomodation/{id}/calendar/token", metrics.MetricProxy(host(handler.GetCalendarToken))).Methods("GET")
+	router.HandleFunc("/api/accomodation/{id}/calendar/token", metrics.MetricProxy(host(handler.RegenerateCalendarToken))).Methods("POST")
+	router.HandleFunc("/api/accomodation/{id}/calendar/import", metrics.MetricProxy(host(handler.ImportCalendarFromUrl))).Methods("POST")
+	router.HandleFunc("/api/accomodation/{id}/calendar/import/file", metrics.MetricProxy(host(handler.ImportCalendarFile))).Methods("POST")
+	router.HandleFunc("/api/accomodation/{id}/calendar/import", metrics.MetricProxy(host(handler.GetCalendarImports))).Methods("GET")
+	router.HandleFunc("/api/accomodation/calendar/import/{id}", metrics.MetricProxy(host(handler.DeleteCalendarImport))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/{id}/acceptReservationType", metrics.MetricProxy(host(handler.UpdateAccommodationAcceptReservationType))).Methods("PUT")
+	router.HandleFunc("/api/accomodation/{id}/checkInOut", metrics.MetricProxy(host(handler.UpdateCheckInOut))).Methods("PUT")
+	router.HandleFunc("/api/accomodation/{id}/preparationDays", metrics.MetricProxy(host(handler.UpdatePreparationDays))).Methods("PUT")
+	router.HandleFunc("/api/accomodation/{id}/occupancyPricing", metrics.MetricProxy(host(handler.UpdateOccupancyPricing))).Methods("PUT")
 	router.HandleFunc("/api/accomodation/search/available", metrics.MetricProxy(handler.SearchAccomodation)).Methods("POST")
+	router.HandleFunc("/api/accomodation/{id}/quote", metrics.MetricProxy(handler.Quote)).Methods("POST")
 	router.HandleFunc("/api/accomodation/for-host/{hostId}", metrics.MetricProxy(handler.FindAccommodationsForHost)).Methods("GET")
 
-	router.HandleFunc("/api/accomodation/image/{filename}", handler.ImageHandler).Methods("GET")
+	router.HandleFunc("/api/accomodation/image/{filename}", metrics.MetricProxy(handler.ImageHandler)).Methods("GET")
 
-	router.HandleFunc("/api/accomodation/delete-all/{hostId}", metrics.MetricProxy(handler.DeleteHostAccomodation)).Methods("DELETE")
-	router.HandleFunc("/api/accomodation/price", metrics.MetricProxy(handler.CreatePrice)).Methods("POST")
-	router.HandleFunc("/api/accomodation/price/{id}", metrics.MetricProxy(handler.UpdatePrice)).Methods("PUT")
-	router.HandleFunc("/api/accomodation/price/{id}", metrics.MetricProxy(handler.DeletePrice)).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/delete-all/{hostId}", metrics.MetricProxy(windbnbService(handler.DeleteHostAccomodation))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/price", metrics.MetricProxy(host(handler.CreatePrice))).Methods("POST")
+	router.HandleFunc("/api/accomodation/price/{id}", metrics.MetricProxy(host(handler.UpdatePrice))).Methods("PUT")
+	router.HandleFunc("/api/accomodation/price/{id}", metrics.MetricProxy(host(handler.DeletePrice))).Methods("DELETE")
 	router.HandleFunc("/api/accomodation/price/for-accomodation/{id}", metrics.MetricProxy(handler.GetPricesForAccomodation)).Methods("GET")
+	router.HandleFunc("/api/accomodation/price/for-accomodation/{id}/history", metrics.MetricProxy(handler.GetPriceHistoryForAccomodation)).Methods("GET")
 
-
-	router.HandleFunc("/api/accomodation/availableTerm", metrics.MetricProxy(handler.CreateAvailableTerm)).Methods("POST")
-	router.HandleFunc("/api/accomodation/availableTerm/{id}", metrics.MetricProxy(handler.UpdateAvailableTerm)).Methods("PUT")
-	router.HandleFunc("/api/accomodation/availableTerm/{id}", metrics.MetricProxy(handler.DeleteAvailableTerm)).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/availableTerm", metrics.MetricProxy(host(handler.CreateAvailableTerm))).Methods("POST")
+	router.HandleFunc("/api/accomodation/availableTerm/{id}", metrics.MetricProxy(host(handler.UpdateAvailableTerm))).Methods("PUT")
+	router.HandleFunc("/api/accomodation/availableTerm/{id}", metrics.MetricProxy(host(handler.DeleteAvailableTerm))).Methods("DELETE")
 	router.HandleFunc("/api/accomodation/availableTerm/for-accomodation/{id}", metrics.MetricProxy(handler.GetAvailableTermsForAccomodation)).Methods("GET")
 
-	router.HandleFunc("/api/accomodation/reservedTerm", metrics.MetricProxy(handler.CreateReservedTerm)).Methods("POST")
-	router.HandleFunc("/api/accomodation/reservedTerm/{id}", metrics.MetricProxy(handler.DeleteReservedTerm)).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/availabilityRule", metrics.MetricProxy(host(handler.CreateAvailabilityRule))).Methods("POST")
+	router.HandleFunc("/api/accomodation/availabilityRule/{id}", metrics.MetricProxy(host(handler.DeleteAvailabilityRule))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/availabilityRule/for-accomodation/{id}", metrics.MetricProxy(handler.GetAvailabilityRulesForAccomodation)).Methods("GET")
+
+	router.HandleFunc("/api/accomodation/stayRule", metrics.MetricProxy(host(handler.CreateStayRule))).Methods("POST")
+	router.HandleFunc("/api/accomodation/stayRule/{id}", metrics.MetricProxy(host(handler.DeleteStayRule))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/stayRule/for-accomodation/{id}", metrics.MetricProxy(handler.GetStayRulesForAccomodation)).Methods("GET")
+
+	router.HandleFunc("/api/accomodation/discountRule", metrics.MetricProxy(host(handler.CreateDiscountRule))).Methods("POST")
+	router.HandleFunc("/api/accomodation/discountRule/{id}", metrics.MetricProxy(host(handler.DeleteDiscountRule))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/discountRule/for-accomodation/{id}", metrics.MetricProxy(handler.GetDiscountRulesForAccomodation)).Methods("GET")
+
+	router.HandleFunc("/api/accomodation/fee", metrics.MetricProxy(host(handler.CreateFee))).Methods("POST")
+	router.HandleFunc("/api/accomodation/fee/{id}", metrics.MetricProxy(host(handler.DeleteFee))).Methods("DELETE")
+	router.HandleFunc("/api/accomodation/fee/for-accomodation/{id}", metrics.MetricProxy(handler.GetFeesForAccomodation)).Methods("GET")
+
+	router.HandleFunc("/api/accomodation/reservedTerm", metrics.MetricProxy(windbnbService(handler.CreateReservedTerm))).Methods("POST")
+	router.HandleFunc("/api/accomodation/reservedTerm/{id}", metrics.MetricProxy(windbnbService(handler.DeleteReservedTerm))).Methods("DELETE")
+
+	router.HandleFunc("/api/accomodation/events/user", metrics.MetricProxy(windbnbService(handler.HandleUserEvent))).Methods("POST")
 
 	router.Path("/metrics").Handler(metrics.MetricsHandler())
 
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (service *AccomodationService) CreateAvailabilityRule(availabilityRule model.AvailabilityRule, hostId uint, ctx context.Context) (model.AvailabilityRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "createAvailabilityRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(availabilityRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.AvailabilityRule{}, err
+	}
+
+	if err := validateAvailabilityRule(availabilityRule); err != nil {
+		tracer.LogError(span, err)
+		return model.AvailabilityRule{}, err
+	}
+
+	return service.Repo.SaveAvailabilityRule(availabilityRule, ctx), nil
+}
+
+func (service *AccomodationService) DeleteAvailabilityRule(id uint64, hostId uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteAvailabilityRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	availabilityRule, err := service.Repo.FindAvailabilityRuleById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return errors.New("availability rule with given id does not exist")
+	}
+
+	_, err = service.findOwnedAccomodation(availabilityRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	return service.Repo.DeleteAvailabilityRule(id, ctx)
+}
+
+func (service *AccomodationService) GetAvailabilityRulesForAccomodation(accomodationId uint, ctx context.Context) []model.AvailabilityRuleDTO {
+	span := tracer.StartSpanFromContext(ctx, "getAvailabilityRulesForAccomodationService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	availabilityRulesDTO := []model.AvailabilityRuleDTO{}
+	for _, availabilityRule := range service.Repo.FindAvailabilityRulesForAccomodation(accomodationId, ctx) {
+		availabilityRulesDTO = append(availabilityRulesDTO, availabilityRule.ToDTO())
+	}
+	return availabilityRulesDTO
+}
+
+// isAvailable reports whether the accomodation can be booked between the given
+// dates. Every night has to be covered either by an available term or by one
+// of the availability rules of the accomodation.
+func (service *AccomodationService) isAvailable(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+	if service.Repo.IsAvailable(accomodationId, startDate, endDate, ctx) {
+		return true
+	}
+
+	availabilityRules := service.Repo.FindAvailabilityRulesForAccomodation(accomodationId, ctx)
+	if len(availabilityRules) == 0 {
+		return false
+	}
+
+	availableTerms := service.Repo.FindAvailableTermsBetween(accomodationId, startDate, endDate, ctx)
+	night := startDate
+	for {
+		nightEnd := night.AddDate(0, 0, 1)
+		if !isAvailableDuring(availableTerms, night, nightEnd) && !isAllowedByRules(availabilityRules, night) {
+			return false
+		}
+		if !nightEnd.Before(endDate) {
+			return true
+		}
+		night = nightEnd
+	}
+}
+
+func isAllowedByRules(availabilityRules []model.AvailabilityRule, day time.Time) bool {
+	for _, availabilityRule := range availabilityRules {
+		if availabilityRule.Allows(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAvailabilityRule(availabilityRule model.AvailabilityRule) error {
+	if err := validateWeekdays(availabilityRule.Weekdays); err != nil {
+		return err
+	}
+	for _, month := range strings.Split(availabilityRule.Months, ",") {
+		if number, err := strconv.Atoi(month); month != "" && (err != nil || number < 1 || number > 12) {
+			return errors.New("month " + month + " does not exist, use numbers from 1 to 12")
+		}
+	}
+	for _, exceptionDate := range strings.Split(availabilityRule.ExceptionDates, ",") {
+		if _, err := time.Parse(model.DateLayout, exceptionDate); exceptionDate != "" && err != nil {
+			return errors.New("exception date " + exceptionDate + " is not in yyyy-mm-dd format")
+		}
+	}
+	if !availabilityRule.StartDate.IsZero() && !availabilityRule.EndDate.IsZero() && availabilityRule.EndDate.Before(availabilityRule.StartDate) {
+		return errors.New("availability rule can not end before it starts")
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windbnb/accomodation-service/ical"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// maxCalendarDays limits how many days a single calendar request can span.
+const maxCalendarDays = 366
+
+func (service *AccomodationService) GetCalendar(accomodationId uint, from time.Time, to time.Time, ctx context.Context) (model.CalendarDTO, error) {
+	span := tracer.StartSpanFromContext(ctx, "getCalendarService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	from, to = model.CalendarDate(from), model.CalendarDate(to)
+	if to.Before(from) {
+		err := errors.New("calendar end date can not be before start date")
+		tracer.LogError(span, err)
+		return model.CalendarDTO{}, err
+	}
+	if to.Sub(from) >= maxCalendarDays*24*time.Hour {
+		err := errors.New("calendar can not span more than 366 days")
+		tracer.LogError(span, err)
+		return model.CalendarDTO{}, err
+	}
+
+	accomodation, err := service.Repo.FindAccomodationById(accomodationId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.CalendarDTO{}, errors.New("accomodation with given id does not exist")
+	}
+
+	rangeEnd := to.AddDate(0, 0, 1)
+	preparationDays := int(accomodation.PreparationDays)
+	availableTerms := service.Repo.FindAvailableTermsBetween(accomodationId, from, rangeEnd, ctx)
+	reservedTerms := service.Repo.FindReservedTermsBetween(accomodationId, from.AddDate(0, 0, -preparationDays), rangeEnd.AddDate(0, 0, preparationDays), ctx)
+	blockedTerms := service.Repo.FindBlockedTermsBetween(accomodationId, from, rangeEnd, ctx)
+	availabilityRules := service.Repo.FindAvailabilityRulesForAccomodation(accomodationId, ctx)
+	prices := service.Repo.FindPricesForAccomodation(accomodationId, from, rangeEnd, ctx)
+
+	calendar := model.CalendarDTO{AccomodationID: accomodationId, From: from, To: to, Days: []model.CalendarDayDTO{}}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		calendarDay := model.CalendarDayDTO{Date: day, Status: model.BLOCKED}
+
+		if isReservedDuring(reservedTerms, accomodation, day, dayEnd) {
+			calendarDay.Status = model.RESERVED
+		} else if isBlockedDuring(blockedTerms, day, dayEnd) || isPreparedDuring(reservedTerms, accomodation, day, dayEnd) {
+			calendarDay.Status = model.BLOCKED
+		} else if isAvailableDuring(availableTerms, day, dayEnd) || isAllowedByRules(availabilityRules, day) {
+			calendarDay.Status = model.AVAILABLE
+		}
+
+		if price, found := priceForDay(prices, day); found {
+			calendarDay.Price = price.Value
+			calendarDay.PriceDuration = price.PriceDuration
+		}
+
+		calendar.Days = append(calendar.Days, calendarDay)
+	}
+
+	return calendar, nil
+}
+
+func (service *AccomodationService) GetCalendarToken(accomodationId uint, hostId uint, ctx context.Context) (string, error) {
+	span := tracer.StartSpanFromContext(ctx, "getCalendarTokenService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return "", err
+	}
+
+	if accomodation.CalendarToken == "" {
+		accomodation.CalendarToken = uuid.New().String()
+		service.Repo.UpdateAccommodation(accomodation, ctx)
+	}
+
+	return accomodation.CalendarToken, nil
+}
+
+func (service *AccomodationService) RegenerateCalendarToken(accomodationId uint, hostId uint, ctx context.Context) (string, error) {
+	span := tracer.StartSpanFromContext(ctx, "regenerateCalendarTokenService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return "", err
+	}
+
+	accomodation.CalendarToken = uuid.New().String()
+	service.Repo.UpdateAccommodation(accomodation, ctx)
+
+	return accomodation.CalendarToken, nil
+}
+
+// ExportCalendar renders the reserved terms of the accomodation, and optionally
+// its available terms, as an iCalendar feed. The token has to match the secret
+// calendar token of the accomodation.
+func (service *AccomodationService) ExportCalendar(accomodationId uint, token string, includeAvailable bool, ctx context.Context) ([]byte, error) {
+	span := tracer.StartSpanFromContext(ctx, "exportCalendarService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.Repo.FindAccomodationById(accomodationId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, errors.New("accomodation with given id does not exist")
+	}
+
+	if accomodation.CalendarToken == "" || subtle.ConstantTimeCompare([]byte(accomodation.CalendarToken), []byte(token)) != 1 {
+		err := errors.New("invalid calendar token")
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	now := service.Clock.Now()
+	calendar := ical.Calendar{ProdID: "-//windbnb//accomodation-service//EN", Name: accomodation.Name}
+	for _, reservedTerm := range service.Repo.GetReservedTermsForAccomodation(accomodationId, ctx) {
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:     fmt.Sprintf("reserved-term-%d@windbnb", reservedTerm.ID),
+			Summary: "Reserved",
+			Start:   reservedTerm.StartDate,
+			End:     reservedTerm.EndDate,
+			Stamp:   now})
+	}
+	if includeAvailable {
+		for _, availableTerm := range service.Repo.GetAvailableTermsForAccomodation(accomodationId, ctx) {
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:         fmt.Sprintf("available-term-%d@windbnb", availableTerm.ID),
+				Summary:     "Available",
+				Start:       availableTerm.StartDate,
+				End:         availableTerm.EndDate,
+				Stamp:       now,
+				Transparent: true})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := calendar.Encode(&buffer); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func overlaps(startDate time.Time, endDate time.Time, from time.Time, to time.Time) bool {
+	return startDate.Before(to) && endDate.After(from)
+}
+
+// isReservedDuring compares the calendar dates of the stays at the
+// accomodation, so the day a stay ends on stays free for the next check-in.
+func isReservedDuring(reservedTerms []model.ReservedTerm, accomodation model.Accomodation, from time.Time, to time.Time) bool {
+	for _, reservedTerm := range reservedTerms {
+		if overlaps(accomodation.DateOf(reservedTerm.StartDate), accomodation.DateOf(reservedTerm.EndDate), from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreparedDuring reports whether the days are kept free to prepare the
+// accomodation before or after one of the reservations.
+func isPreparedDuring(reservedTerms []model.ReservedTerm, accomodation model.Accomodation, from time.Time, to time.Time) bool {
+	preparationDays := int(accomodation.PreparationDays)
+	for _, reservedTerm := range reservedTerms {
+		if overlaps(accomodation.DateOf(reservedTerm.StartDate).AddDate(0, 0, -preparationDays), accomodation.DateOf(reservedTerm.EndDate).AddDate(0, 0, preparationDays), from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlockedDuring(blockedTerms []model.BlockedTerm, from time.Time, to time.Time) bool {
+	for _, blockedTerm := range blockedTerms {
+		if overlaps(blockedTerm.StartDate, blockedTerm.EndDate, from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAvailableDuring(availableTerms []model.AvailableTerm, from time.Time, to time.Time) bool {
+	for _, availableTerm := range availableTerms {
+		if overlaps(availableTerm.StartDate, availableTerm.EndDate, from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// priceForDay picks the price that applies to the given day. Holiday prices
+// win over everything else, weekend prices apply only on Saturdays and Sundays
+// and regular prices are used otherwise.
+func priceForDay(prices []model.Price, day time.Time) (model.Price, bool) {
+	dayEnd := day.AddDate(0, 0, 1)
+	isWeekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+
+	var regular, weekend *model.Price
+	for i := range prices {
+		price := &prices[i]
+		if !overlaps(price.StartDate, price.EndDate, day, dayEnd) {
+			continue
+		}
+		switch price.PriceDuration {
+		case model.HOLIDAY:
+			return *price, true
+		case model.WEEKEND:
+			if isWeekend && weekend == nil {
+				weekend = price
+			}
+		default:
+			if regular == nil {
+				regular = price
+			}
+		}
+	}
+
+	if weekend != nil {
+		return *weekend, true
+	}
+	if regular != nil {
+		return *regular, true
+	}
+	return model.Price{}, false
+}
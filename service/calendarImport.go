@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/windbnb/accomodation-service/ical"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// maxCalendarSize limits how much of an external calendar is read.
+const maxCalendarSize = 5 << 20
+
+var defaultHttpClient = &http.Client{Timeout: 30 * time.Second, Transport: &tracer.Transport{Base: publicOnlyTransport()}}
+
+// publicOnlyTransport fetches calendars from public addresses only. The check
+// runs on every connection after the host is resolved, so neither a host name
+// pointing inside the network nor a redirect can reach internal services.
+func publicOnlyTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: refuseInternalAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// nonPublicNetworks are the ranges that IsGlobalUnicast lets through but that
+// are still not reachable from the internet, or that embed an IPv4 address a
+// gateway could translate into an internal one.
+var nonPublicNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"::/96",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"2001:db8::/32",
+	"2002::/16",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPublicAddress reports whether calendars may be fetched from the address.
+// Only global unicast addresses outside of private and otherwise reserved
+// ranges are public. IPv4-mapped IPv6 addresses are judged as IPv4.
+func IsPublicAddress(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func refuseInternalAddress(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicAddress(ip) {
+		return fmt.Errorf("calendar address %s is not public", host)
+	}
+	return nil
+}
+
+func (service *AccomodationService) ImportCalendarFromUrl(accomodationId uint, hostId uint, calendarUrl string, ctx context.Context) (model.CalendarImport, error) {
+	span := tracer.StartSpanFromContext(ctx, "importCalendarFromUrlService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.CalendarImport{}, err
+	}
+
+	calendarUrl, err = normalizeCalendarUrl(calendarUrl)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.CalendarImport{}, err
+	}
+
+	calendarImport := service.Repo.SaveCalendarImport(model.CalendarImport{AccomodationID: accomodationId, Url: calendarUrl}, ctx)
+	return service.syncCalendarImport(calendarImport, ctx), nil
+}
+
+// ImportCalendarFile blocks the busy periods from an uploaded calendar. All
+// uploads of an accomodation share one import, so uploading the same file
+// again updates the existing blocked terms instead of duplicating them.
+func (service *AccomodationService) ImportCalendarFile(accomodationId uint, hostId uint, file io.Reader, ctx context.Context) (model.CalendarImport, error) {
+	span := tracer.StartSpanFromContext(ctx, "importCalendarFileService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.CalendarImport{}, err
+	}
+
+	calendar, err := ical.Decode(io.LimitReader(file, maxCalendarSize))
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.CalendarImport{}, err
+	}
+
+	var calendarImport model.CalendarImport
+	for _, existingImport := range service.Repo.FindCalendarImportsForAccomodation(accomodationId, ctx) {
+		if existingImport.Url == "" {
+			calendarImport = existingImport
+			break
+		}
+	}
+	if calendarImport.ID == 0 {
+		calendarImport = service.Repo.SaveCalendarImport(model.CalendarImport{AccomodationID: accomodationId}, ctx)
+	}
+
+	service.syncBlockedTerms(calendarImport, calendar.Events, false, ctx)
+
+	syncedAt := service.Clock.Now()
+	calendarImport.LastSyncedAt = &syncedAt
+	calendarImport.LastError = ""
+	return service.Repo.UpdateCalendarImport(calendarImport, ctx), nil
+}
+
+func (service *AccomodationService) GetCalendarImports(accomodationId uint, hostId uint, ctx context.Context) ([]model.CalendarImportDTO, error) {
+	span := tracer.StartSpanFromContext(ctx, "getCalendarImportsService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	calendarImportsDTO := []model.CalendarImportDTO{}
+	for _, calendarImport := range service.Repo.FindCalendarImportsForAccomodation(accomodationId, ctx) {
+		calendarImportsDTO = append(calendarImportsDTO, calendarImport.ToDTO())
+	}
+	return calendarImportsDTO, nil
+}
+
+func (service *AccomodationService) DeleteCalendarImport(id uint64, hostId uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteCalendarImportService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	calendarImport, err := service.Repo.FindCalendarImportById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return errors.New("calendar import with given id does not exist")
+	}
+
+	_, err = service.findOwnedAccomodation(calendarImport.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	return service.Repo.DeleteCalendarImport(id, ctx)
+}
+
+// SyncCalendarImports fetches every imported calendar Url again and updates
+// the blocked terms of its accomodation.
+func (service *AccomodationService) SyncCalendarImports(ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "syncCalendarImportsService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	for _, calendarImport := range service.Repo.FindUrlCalendarImports(ctx) {
+		service.syncCalendarImport(calendarImport, ctx)
+	}
+}
+
+// RunCalendarSync re-syncs imported calendars every interval until the
+// context is cancelled.
+func (service *AccomodationService) RunCalendarSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			service.SyncCalendarImports(ctx)
+		}
+	}
+}
+
+func (service *AccomodationService) syncCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "syncCalendarImportService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	calendar, err := service.fetchCalendar(calendarImport.Url, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		log.Printf("syncing calendar import %d failed: %v", calendarImport.ID, err)
+		calendarImport.LastError = err.Error()
+		return service.Repo.UpdateCalendarImport(calendarImport, ctx)
+	}
+
+	service.syncBlockedTerms(calendarImport, calendar.Events, true, ctx)
+
+	syncedAt := service.Clock.Now()
+	calendarImport.LastSyncedAt = &syncedAt
+	calendarImport.LastError = ""
+	return service.Repo.UpdateCalendarImport(calendarImport, ctx)
+}
+
+func (service *AccomodationService) fetchCalendar(calendarUrl string, ctx context.Context) (ical.Calendar, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, calendarUrl, nil)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	request.Header.Set("Accept", "text/calendar")
+
+	response, err := service.httpClient().Do(request)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return ical.Calendar{}, fmt.Errorf("calendar responded with status %d", response.StatusCode)
+	}
+
+	return ical.Decode(io.LimitReader(response.Body, maxCalendarSize))
+}
+
+// syncBlockedTerms stores busy events as blocked terms, matching them to the
+// already imported ones by UID. When removeMissing is set, blocked terms whose
+// event is no longer in the calendar are deleted.
+func (service *AccomodationService) syncBlockedTerms(calendarImport model.CalendarImport, events []ical.Event, removeMissing bool, ctx context.Context) {
+	existingTerms := map[string]model.BlockedTerm{}
+	for _, blockedTerm := range service.Repo.FindBlockedTermsForCalendarImport(calendarImport.ID, ctx) {
+		existingTerms[blockedTerm.UID] = blockedTerm
+	}
+
+	seen := map[string]bool{}
+	for _, event := range events {
+		if event.Cancelled || event.Transparent {
+			continue
+		}
+
+		uid := event.UID
+		if uid == "" {
+			uid = fmt.Sprintf("%d-%d", event.Start.Unix(), event.End.Unix())
+		}
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		blockedTerm, found := existingTerms[uid]
+		if !found {
+			service.Repo.SaveBlockedTerm(model.BlockedTerm{
+				StartDate:        event.Start,
+				EndDate:          event.End,
+				AccomodationID:   calendarImport.AccomodationID,
+				CalendarImportID: calendarImport.ID,
+				UID:              uid}, ctx)
+		} else if !blockedTerm.StartDate.Equal(event.Start) || !blockedTerm.EndDate.Equal(event.End) {
+			blockedTerm.StartDate = event.Start
+			blockedTerm.EndDate = event.End
+			service.Repo.UpdateBlockedTerm(blockedTerm, ctx)
+		}
+	}
+
+	if !removeMissing {
+		return
+	}
+	for uid, blockedTerm := range existingTerms {
+		if !seen[uid] {
+			service.Repo.DeleteBlockedTerm(blockedTerm.ID, ctx)
+		}
+	}
+}
+
+func (service *AccomodationService) httpClient() *http.Client {
+	if service.HttpClient != nil {
+		return service.HttpClient
+	}
+	return defaultHttpClient
+}
+
+// normalizeCalendarUrl accepts http(s) Urls as well as the webcal scheme most
+// platforms use for calendar subscriptions.
+func normalizeCalendarUrl(calendarUrl string) (string, error) {
+	parsedUrl, err := url.Parse(strings.TrimSpace(calendarUrl))
+	if err != nil || parsedUrl.Host == "" {
+		return "", errors.New("calendar url is not valid")
+	}
+
+	switch strings.ToLower(parsedUrl.Scheme) {
+	case "http", "https":
+	case "webcal", "webcals":
+		parsedUrl.Scheme = "https"
+	default:
+		return "", errors.New("calendar url has to use http, https or webcal")
+	}
+	return parsedUrl.String(), nil
+}
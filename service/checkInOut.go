@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// maxPreparationDays limits how long an accomodation can stay closed between
+// two reservations.
+const maxPreparationDays = 30
+
+func (service *AccomodationService) UpdateCheckInOut(accomodationId uint, checkInOutDTO model.CheckInOutDTO, hostId uint, ctx context.Context) (model.Accomodation, error) {
+	span := tracer.StartSpanFromContext(ctx, "updateCheckInOutService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	accomodation.CheckInTime = checkInOutDTO.CheckInTime
+	accomodation.CheckOutTime = checkInOutDTO.CheckOutTime
+	accomodation.CheckInDays = strings.Join(checkInOutDTO.CheckInDays, ",")
+	if checkInOutDTO.TimeZone != "" {
+		accomodation.TimeZone = checkInOutDTO.TimeZone
+	}
+	if err := service.ValidateTurnover(accomodation); err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	return service.Repo.UpdateAccommodation(accomodation, ctx), nil
+}
+
+func (service *AccomodationService) UpdatePreparationDays(accomodationId uint, preparationDays uint, hostId uint, ctx context.Context) (model.Accomodation, error) {
+	span := tracer.StartSpanFromContext(ctx, "updatePreparationDaysService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	accomodation.PreparationDays = preparationDays
+	if err := service.ValidateTurnover(accomodation); err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	return service.Repo.UpdateAccommodation(accomodation, ctx), nil
+}
+
+// ValidateTurnover checks the settings that control how one stay follows
+// another. Check-out has to come before check-in so that one stay can end on
+// the same day the next one starts, unless preparation days keep them apart.
+// Both times are read in the time zone of the accomodation.
+func (service *AccomodationService) ValidateTurnover(accomodation model.Accomodation) error {
+	if accomodation.TimeZone != "" {
+		if _, err := model.LoadLocation(accomodation.TimeZone); err != nil || accomodation.TimeZone == "Local" {
+			return errors.New("time zone " + accomodation.TimeZone + " does not exist")
+		}
+	}
+	if accomodation.PreparationDays > maxPreparationDays {
+		return fmt.Errorf("preparation days can not exceed %d", maxPreparationDays)
+	}
+
+	if accomodation.CheckInTime == "" {
+		accomodation.CheckInTime = model.DefaultCheckInTime
+	}
+	if accomodation.CheckOutTime == "" {
+		accomodation.CheckOutTime = model.DefaultCheckOutTime
+	}
+
+	checkIn, err := time.Parse(model.ClockLayout, accomodation.CheckInTime)
+	if err != nil {
+		return errors.New("check-in time " + accomodation.CheckInTime + " is not in hh:mm format")
+	}
+	checkOut, err := time.Parse(model.ClockLayout, accomodation.CheckOutTime)
+	if err != nil {
+		return errors.New("check-out time " + accomodation.CheckOutTime + " is not in hh:mm format")
+	}
+	if checkOut.After(checkIn) {
+		return errors.New("check-out time can not be after check-in time")
+	}
+	return validateWeekdays(accomodation.CheckInDays)
+}
+
+// checkCheckIn returns an error when guests can not arrive on the given day.
+func checkCheckIn(accomodation model.Accomodation, startDate time.Time) error {
+	if !accomodation.AllowsCheckIn(startDate) {
+		return fmt.Errorf("check-in is not allowed on %s", startDate.Weekday())
+	}
+	return nil
+}
+
+// reservationWindow returns the period that has to be free of other
+// reservations for a stay between the given days, including the preparation
+// days needed on both sides of it.
+func reservationWindow(accomodation model.Accomodation, startDate time.Time, endDate time.Time) (time.Time, time.Time) {
+	preparationDays := int(accomodation.PreparationDays)
+	return accomodation.CheckIn(startDate).AddDate(0, 0, -preparationDays), accomodation.CheckOut(endDate).AddDate(0, 0, preparationDays)
+}
+
+func validateWeekdays(weekdays string) error {
+	validWeekdays := map[string]bool{}
+	for _, weekday := range model.Weekdays {
+		validWeekdays[weekday] = true
+	}
+
+	for _, weekday := range strings.Split(weekdays, ",") {
+		if weekday != "" && !validWeekdays[weekday] {
+			return errors.New("weekday " + weekday + " does not exist, use MO, TU, WE, TH, FR, SA or SU")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (service *AccomodationService) CreateDiscountRule(discountRule model.DiscountRule, hostId uint, ctx context.Context) (model.DiscountRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "createDiscountRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(discountRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.DiscountRule{}, err
+	}
+
+	if discountRule.Type == "" {
+		discountRule.Type = model.LENGTH_OF_STAY
+	}
+	if err := validateDiscountRule(discountRule); err != nil {
+		tracer.LogError(span, err)
+		return model.DiscountRule{}, err
+	}
+
+	return service.Repo.SaveDiscountRule(discountRule, ctx), nil
+}
+
+func (service *AccomodationService) DeleteDiscountRule(id uint64, hostId uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteDiscountRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	discountRule, err := service.Repo.FindDiscountRuleById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return errors.New("discount rule with given id does not exist")
+	}
+
+	_, err = service.findOwnedAccomodation(discountRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	return service.Repo.DeleteDiscountRule(id, ctx)
+}
+
+func (service *AccomodationService) GetDiscountRulesForAccomodation(accomodationId uint, ctx context.Context) []model.DiscountRuleDTO {
+	span := tracer.StartSpanFromContext(ctx, "getDiscountRulesForAccomodationService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	discountRulesDTO := []model.DiscountRuleDTO{}
+	for _, discountRule := range service.Repo.FindDiscountRulesForAccomodation(accomodationId, ctx) {
+		discountRulesDTO = append(discountRulesDTO, discountRule.ToDTO())
+	}
+	return discountRulesDTO
+}
+
+// applyDiscounts gives the largest discount of each type among the rules the
+// stay qualifies for. Every discount is taken from the undiscounted subtotal,
+// but together they never take more than the whole subtotal.
+func applyDiscounts(discountRules []model.DiscountRule, nights int, daysBeforeCheckIn int, subtotal model.Money) []model.AppliedDiscountDTO {
+	best := map[model.DiscountType]model.DiscountRule{}
+	for _, discountRule := range discountRules {
+		if discountRule.Type == "" {
+			discountRule.Type = model.LENGTH_OF_STAY
+		}
+		if !discountRule.Applies(nights, daysBeforeCheckIn) {
+			continue
+		}
+		if current, found := best[discountRule.Type]; !found || discountRule.Percentage > current.Percentage {
+			best[discountRule.Type] = discountRule
+		}
+	}
+
+	appliedDiscounts := []model.AppliedDiscountDTO{}
+	remaining := subtotal
+	for _, discountType := range []model.DiscountType{model.LENGTH_OF_STAY, model.EARLY_BIRD, model.LAST_MINUTE} {
+		discountRule, found := best[discountType]
+		if !found {
+			continue
+		}
+		amount := subtotal.Percent(float64(discountRule.Percentage))
+		if remaining.Sub(amount).IsNegative() {
+			amount = remaining
+		}
+		remaining = remaining.Sub(amount)
+		appliedDiscounts = append(appliedDiscounts, model.AppliedDiscountDTO{
+			DiscountRuleId:    discountRule.ID,
+			Type:              discountRule.Type,
+			MinimumNights:     discountRule.MinimumNights,
+			DaysBeforeCheckIn: discountRule.DaysBeforeCheckIn,
+			Percentage:        discountRule.Percentage,
+			Amount:            amount,
+		})
+	}
+	return appliedDiscounts
+}
+
+func validateDiscountRule(discountRule model.DiscountRule) error {
+	switch discountRule.Type {
+	case model.LENGTH_OF_STAY:
+		if discountRule.MinimumNights == 0 {
+			return errors.New("length of stay discount needs a minimum number of nights")
+		}
+	case model.EARLY_BIRD:
+		if discountRule.DaysBeforeCheckIn == 0 {
+			return errors.New("early bird discount needs a number of days before check-in")
+		}
+	case model.LAST_MINUTE:
+	default:
+		return errors.New("discount type " + string(discountRule.Type) + " does not exist")
+	}
+	if discountRule.Percentage <= 0 || discountRule.Percentage > 100 {
+		return errors.New("discount percentage has to be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/windbnb/accomodation-service/model"
+)
+
+func validateDisplayCurrency(currency string) error {
+	if currency != "" && !model.IsCurrency(currency) {
+		return errors.New("currency " + currency + " is not supported")
+	}
+	return nil
+}
+
+// addDisplayPrice converts the prices of a stay into the currency the guest
+// asked for. The prices in the host currency are left as they are.
+func (service *AccomodationService) addDisplayPrice(searchAccomodationReturnDTO *model.SearchAccomodationReturnDTO, currency string, ctx context.Context) error {
+	if currency == "" {
+		return nil
+	}
+	if err := validateDisplayCurrency(currency); err != nil {
+		return err
+	}
+	if service.ExchangeRates == nil {
+		return errors.New("prices can not be shown in " + currency + " because exchange rates are not configured")
+	}
+
+	hostCurrency := searchAccomodationReturnDTO.TotalPrice.Currency
+	rate, err := service.ExchangeRates.Rate(hostCurrency, currency, ctx)
+	if err != nil {
+		return err
+	}
+
+	searchAccomodationReturnDTO.DisplayPrice = &model.DisplayPriceDTO{
+		Currency:   currency,
+		Rate:       rate,
+		Price:      searchAccomodationReturnDTO.Price.Convert(rate, currency),
+		TotalPrice: searchAccomodationReturnDTO.TotalPrice.Convert(rate, currency),
+	}
+	return nil
+}
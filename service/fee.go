@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (service *AccomodationService) CreateFee(fee model.Fee, hostId uint, ctx context.Context) (model.Fee, error) {
+	span := tracer.StartSpanFromContext(ctx, "createFeeService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(fee.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Fee{}, err
+	}
+
+	// Percentage fees do not need an amount, so one left out is taken to be
+	// zero in the currency of the accomodation.
+	if fee.Amount.Currency == "" && fee.Amount.Amount == 0 {
+		fee.Amount.Currency = accomodation.CurrencyCode()
+	}
+	if fee.Amount.Currency != accomodation.CurrencyCode() {
+		err := errors.New("fee amount has to be in " + accomodation.CurrencyCode())
+		tracer.LogError(span, err)
+		return model.Fee{}, err
+	}
+
+	if err := validateFee(fee); err != nil {
+		tracer.LogError(span, err)
+		return model.Fee{}, err
+	}
+
+	return service.Repo.SaveFee(fee, ctx), nil
+}
+
+func (service *AccomodationService) DeleteFee(id uint64, hostId uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteFeeService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	fee, err := service.Repo.FindFeeById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return errors.New("fee with given id does not exist")
+	}
+
+	_, err = service.findOwnedAccomodation(fee.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	return service.Repo.DeleteFee(id, ctx)
+}
+
+func (service *AccomodationService) GetFeesForAccomodation(accomodationId uint, ctx context.Context) []model.FeeDTO {
+	span := tracer.StartSpanFromContext(ctx, "getFeesForAccomodationService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	feesDTO := []model.FeeDTO{}
+	for _, fee := range service.Repo.FindFeesForAccomodation(accomodationId, ctx) {
+		feesDTO = append(feesDTO, fee.ToDTO())
+	}
+	return feesDTO
+}
+
+// applyFees charges the fees of the given kind for a stay whose price after
+// discounts is discountedSubtotal.
+func applyFees(fees []model.Fee, kind model.FeeKind, nights int, guests uint, discountedSubtotal model.Money) ([]model.AppliedFeeDTO, model.Money) {
+	appliedFees := []model.AppliedFeeDTO{}
+	total := model.NewMoney(0, discountedSubtotal.Currency)
+	for _, fee := range fees {
+		if fee.Kind != kind {
+			continue
+		}
+
+		var amount model.Money
+		switch fee.Basis {
+		case model.PER_STAY:
+			amount = fee.Amount
+		case model.PER_NIGHT:
+			amount = fee.Amount.Times(int64(nights))
+		case model.PER_GUEST_NIGHT:
+			amount = fee.Amount.Times(int64(guests) * int64(nights))
+		case model.PERCENTAGE:
+			amount = discountedSubtotal.Percent(float64(fee.Percentage))
+		}
+
+		appliedFee := model.AppliedFeeDTO{FeeId: fee.ID, Name: fee.Name, Basis: fee.Basis, Amount: fee.Amount, Percentage: fee.Percentage, Total: amount}
+		appliedFees = append(appliedFees, appliedFee)
+		total = total.Add(appliedFee.Total)
+	}
+	return appliedFees, total
+}
+
+func validateFee(fee model.Fee) error {
+	if fee.Name == "" {
+		return errors.New("fee needs a name")
+	}
+	if fee.Kind != model.FEE && fee.Kind != model.TAX {
+		return errors.New("fee kind " + string(fee.Kind) + " does not exist, use FEE or TAX")
+	}
+	switch fee.Basis {
+	case model.PER_STAY, model.PER_NIGHT, model.PER_GUEST_NIGHT:
+	case model.PERCENTAGE:
+		if fee.Percentage < 0 {
+			return errors.New("fee percentage can not be negative")
+		}
+		if fee.Percentage > 100 {
+			return errors.New("percentage fee can not exceed 100")
+		}
+	default:
+		return errors.New("fee basis " + string(fee.Basis) + " does not exist")
+	}
+	if fee.Amount.IsNegative() {
+		return errors.New("fee amount can not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (service *AccomodationService) UpdateOccupancyPricing(accomodationId uint, occupancyPricingDTO model.OccupancyPricingDTO, hostId uint, ctx context.Context) (model.Accomodation, error) {
+	span := tracer.StartSpanFromContext(ctx, "updateOccupancyPricingService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := service.findOwnedAccomodation(accomodationId, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	accomodation.PriceType = occupancyPricingDTO.PriceType
+	accomodation.BaseOccupancy = occupancyPricingDTO.BaseOccupancy
+	accomodation.ExtraGuestFee = occupancyPricingDTO.ExtraGuestFee
+	if err := service.ValidatePricing(accomodation); err != nil {
+		tracer.LogError(span, err)
+		return model.Accomodation{}, err
+	}
+
+	return service.Repo.UpdateAccommodation(accomodation, ctx), nil
+}
+
+// ValidatePricing checks the price type of an accomodation together with the
+// occupancy settings that PER_OCCUPANCY pricing depends on.
+func (service *AccomodationService) ValidatePricing(accomodation model.Accomodation) error {
+	if !model.IsCurrency(accomodation.CurrencyCode()) {
+		return errors.New("currency " + accomodation.Currency + " is not supported")
+	}
+
+	switch accomodation.PriceType {
+	case model.PER_GUEST, model.PER_ACCOMODATION_UNIT:
+		return nil
+	case model.PER_OCCUPANCY:
+	default:
+		return errors.New("price type " + string(accomodation.PriceType) + " does not exist")
+	}
+
+	if accomodation.BaseOccupancy == 0 {
+		return errors.New("base occupancy has to be at least one guest")
+	}
+	if accomodation.MaximumGuests > 0 && accomodation.BaseOccupancy > accomodation.MaximumGuests {
+		return errors.New("base occupancy can not be higher than the maximum number of guests")
+	}
+	if accomodation.ExtraGuestFee.IsNegative() {
+		return errors.New("extra guest fee can not be negative")
+	}
+	if accomodation.ExtraGuestFee.Currency != "" && accomodation.ExtraGuestFee.Currency != accomodation.CurrencyCode() {
+		return errors.New("extra guest fee has to be in " + accomodation.CurrencyCode())
+	}
+	return nil
+}
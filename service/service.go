@@ -3,29 +3,44 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/windbnb/accomodation-service/clock"
+	"github.com/windbnb/accomodation-service/exchange"
 	"github.com/windbnb/accomodation-service/model"
 	"github.com/windbnb/accomodation-service/repository"
 	"github.com/windbnb/accomodation-service/tracer"
 )
 
 type AccomodationService struct {
-	Repo repository.IRepository
+	Repo       repository.IRepository
+	HttpClient *http.Client
+	// ExchangeRates converts prices into the display currency of searches
+	// and quotes.
+	ExchangeRates exchange.Provider
+	// ServiceToken authenticates other services allowed to change reserved
+	// terms.
+	ServiceToken string
+	// Clock tells the time that lead times and price versions are based on.
+	Clock clock.Func
 }
 
 func (s *AccomodationService) SaveAccomodation(accomodation model.Accomodation, ctx context.Context) model.Accomodation {
 	span := tracer.StartSpanFromContext(ctx, "saveAccomodationService")
 	defer span.Finish()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	accomodation.CalendarToken = uuid.New().String()
 	return s.Repo.SaveAccomodation(accomodation, ctx)
 }
 
 func (s *AccomodationService) UpdateAccommodationAcceptReservationType(accommodationId uint, acceptReservationType model.AcceptReservationType, hostId uint, ctx context.Context) (*model.Accomodation, error) {
 	span := tracer.StartSpanFromContext(ctx, "acceptReservationTypeService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	if acceptReservationType != model.MANUAL && acceptReservationType != model.AUTOMATICALLY {
 		return nil, errors.New("Given type does not exist")
@@ -47,63 +62,152 @@ func (s *AccomodationService) UpdateAccommodationAcceptReservationType(accommoda
 	return &accommodation, nil
 }
 
-func (s *AccomodationService) SaveAccomodationImage(image model.AccomodationImage) model.AccomodationImage {
-	return s.Repo.SaveAccomodationImage(image)
+func (s *AccomodationService) SaveAccomodationImage(image model.AccomodationImage, ctx context.Context) model.AccomodationImage {
+	span := tracer.StartSpanFromContext(ctx, "saveAccomodationImageService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	return s.Repo.SaveAccomodationImage(image, ctx)
 }
 
 func (s *AccomodationService) DeleteHostAccomodation(hostId uint, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deleteHostAccomodationService")
 	defer span.Finish()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	return s.Repo.DeleteHostAccomodation(hostId, ctx)
 }
 
-func (s *AccomodationService) SavePrice(price model.Price) model.Price {
-	return s.Repo.SavePrice(price)
+func (s *AccomodationService) SavePrice(price model.Price, hostId uint, ctx context.Context) (model.Price, error) {
+	span := tracer.StartSpanFromContext(ctx, "savePriceService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accomodation, err := s.findOwnedAccomodation(price.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	if err := s.ValidatePriceCurrency(price, accomodation); err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+
+	price.Active = true
+	return s.Repo.SavePrice(price, ctx), nil
 }
 
-func (s *AccomodationService) SaveAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+func (s *AccomodationService) SaveAvailableTerm(availableTerm model.AvailableTerm, hostId uint, ctx context.Context) (model.AvailableTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "saveAvailableTermService")
 	defer span.Finish()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
-	return s.Repo.SaveAvailableTerm(availableTerm, ctx)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	if _, err := s.findOwnedAccomodation(availableTerm.AccomodationID, hostId, ctx); err != nil {
+		tracer.LogError(span, err)
+		return model.AvailableTerm{}, err
+	}
+	return s.Repo.SaveAvailableTerm(availableTerm, ctx), nil
 }
 
-func (s *AccomodationService) SaveReservedTerm(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
+func (s *AccomodationService) SaveReservedTerm(reservedTerm model.ReservedTerm, ctx context.Context) (model.ReservedTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "saveReservedTermService")
 	defer span.Finish()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
-	return s.Repo.SaveReservedTerm(reservedTerm, ctx)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	reservedTerm.StartDate = model.CalendarDate(reservedTerm.StartDate)
+	reservedTerm.EndDate = model.CalendarDate(reservedTerm.EndDate)
+	accommodation, err := s.Repo.FindAccomodationById(reservedTerm.AccomodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.ReservedTerm{}, errors.New("accomodation with given id does not exist")
+	}
+	if err := checkCheckIn(accommodation, reservedTerm.StartDate); err != nil {
+		tracer.LogError(span, err)
+		return model.ReservedTerm{}, err
+	}
+	if err := s.checkStayLength(reservedTerm.AccomodationID, reservedTerm.StartDate, reservedTerm.EndDate, ctx); err != nil {
+		tracer.LogError(span, err)
+		return model.ReservedTerm{}, err
+	}
+
+	reservedTerm.StartDate = accommodation.CheckIn(reservedTerm.StartDate)
+	reservedTerm.EndDate = accommodation.CheckOut(reservedTerm.EndDate)
+	return s.Repo.SaveReservedTerm(reservedTerm, ctx), nil
 }
 
-func (s *AccomodationService) UpdatePrice(price model.Price, id uint64, ctx context.Context) (model.Price, error) {
+func (s *AccomodationService) UpdatePrice(price model.Price, id uint64, hostId uint, ctx context.Context) (model.Price, error) {
 	span := tracer.StartSpanFromContext(ctx, "updatePriceService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
-	var priceToUpdate model.Price
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	priceToUpdate, err := s.FindPriceById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	accomodation, err := s.findOwnedAccomodation(priceToUpdate.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	if !priceToUpdate.Active {
+		err := errors.New("price with given id is no longer active")
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	if err := s.ValidatePriceCurrency(price, accomodation); err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
 
-	priceToUpdate, _ = s.FindPriceById(id, ctx)
-	if priceToUpdate.ID != 0 {
-		priceToUpdate.StartDate = price.StartDate
-		priceToUpdate.EndDate = price.EndDate
-		priceToUpdate.Value = price.Value
-		s.Repo.UpdatePrice(priceToUpdate, ctx)
+	// Prices are never changed in place: the current version is deactivated
+	// and replaced, so the history shows what a stay cost at any moment.
+	now := s.Clock.Now()
+	newPrice := model.Price{
+		StartDate:       price.StartDate,
+		EndDate:         price.EndDate,
+		Value:           price.Value,
+		PriceDuration:   priceToUpdate.PriceDuration,
+		AccomodationID:  priceToUpdate.AccomodationID,
+		Active:          true,
+		PreviousPriceID: priceToUpdate.ID,
 	}
+	newPrice.CreatedAt = now
+	priceToUpdate.Active = false
+	priceToUpdate.DeactivatedAt = &now
 
-	return priceToUpdate, nil
+	savedPrice, err := s.Repo.SavePriceVersion(priceToUpdate, newPrice, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.Price{}, err
+	}
+	return savedPrice, nil
+}
+
+// ValidatePriceCurrency checks that a price is given in the currency the
+// accomodation is priced in, so that amounts of a stay can be added up.
+func (s *AccomodationService) ValidatePriceCurrency(price model.Price, accomodation model.Accomodation) error {
+	if price.Value.Currency != accomodation.CurrencyCode() {
+		return errors.New("price has to be in " + accomodation.CurrencyCode())
+	}
+	if price.Value.IsNegative() {
+		return errors.New("price can not be negative")
+	}
+	return nil
 }
 
-func (s *AccomodationService) UpdateAvailableTerm(availableTerm model.AvailableTerm, id uint64, ctx context.Context) (model.AvailableTerm, error) {
+func (s *AccomodationService) UpdateAvailableTerm(availableTerm model.AvailableTerm, id uint64, hostId uint, ctx context.Context) (model.AvailableTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "updateAvailableTermService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var availableTermToUpdate model.AvailableTerm
 
 	availableTermToUpdate, _ = s.FindAvailableTermById(id, ctx)
 	if availableTermToUpdate.ID != 0 {
+		if _, err := s.findOwnedAccomodation(availableTermToUpdate.AccomodationID, hostId, ctx); err != nil {
+			tracer.LogError(span, err)
+			return model.AvailableTerm{}, err
+		}
 		availableTermToUpdate.StartDate = availableTerm.StartDate
 		availableTermToUpdate.EndDate = availableTerm.EndDate
 		s.Repo.UpdateAvailableTerm(availableTermToUpdate, ctx)
@@ -112,10 +216,10 @@ func (s *AccomodationService) UpdateAvailableTerm(availableTerm model.AvailableT
 	return availableTermToUpdate, nil
 }
 
-func (s *AccomodationService) DeletePrice(id uint64, ctx context.Context) error {
+func (s *AccomodationService) DeletePrice(id uint64, hostId uint, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deletePriceService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var priceToDelete model.Price
 
 	priceToDelete, _ = s.FindPriceById(id, ctx)
@@ -124,14 +228,25 @@ func (s *AccomodationService) DeletePrice(id uint64, ctx context.Context) error
 		tracer.LogError(span, err)
 		return err
 	}
+	if _, err := s.findOwnedAccomodation(priceToDelete.AccomodationID, hostId, ctx); err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
 
-	return s.Repo.DeletePrice(id, ctx)
+	// Deleted prices are only deactivated so that they stay in the history.
+	if priceToDelete.Active {
+		now := s.Clock.Now()
+		priceToDelete.Active = false
+		priceToDelete.DeactivatedAt = &now
+		s.Repo.UpdatePrice(priceToDelete, ctx)
+	}
+	return nil
 }
 
-func (s *AccomodationService) DeleteAvailableTerm(id uint64, ctx context.Context) error {
+func (s *AccomodationService) DeleteAvailableTerm(id uint64, hostId uint, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deleteAvailableTermService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var availableTermToDelete model.AvailableTerm
 
 	availableTermToDelete, _ = s.FindAvailableTermById(id, ctx)
@@ -140,14 +255,18 @@ func (s *AccomodationService) DeleteAvailableTerm(id uint64, ctx context.Context
 		tracer.LogError(span, err)
 		return err
 	}
+	if _, err := s.findOwnedAccomodation(availableTermToDelete.AccomodationID, hostId, ctx); err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
 
-	return s.Repo.DeleteAvailableTerm(id)
+	return s.Repo.DeleteAvailableTerm(id, ctx)
 }
 
 func (s *AccomodationService) DeleteReservedTerm(id uint64, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deleteReservedTermService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	var reservedTermToDelete model.ReservedTerm
 
 	reservedTermToDelete, _ = s.FindReservedTermById(id, ctx)
@@ -157,13 +276,13 @@ func (s *AccomodationService) DeleteReservedTerm(id uint64, ctx context.Context)
 		return err
 	}
 
-	return s.Repo.DeleteReservedTerm(id)
+	return s.Repo.DeleteReservedTerm(id, ctx)
 }
 
 func (service *AccomodationService) FindAccomodationById(id uint, ctx context.Context) (model.Accomodation, error) {
 	span := tracer.StartSpanFromContext(ctx, "findAccomodationByIdService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	accomodation, err := service.Repo.FindAccomodationById(id, ctx)
 
@@ -178,7 +297,7 @@ func (service *AccomodationService) FindAccomodationById(id uint, ctx context.Co
 func (s *AccomodationService) FindPriceById(id uint64, ctx context.Context) (model.Price, error) {
 	span := tracer.StartSpanFromContext(ctx, "findPriceByIdService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	price, err := s.Repo.FindPriceById(id, ctx)
 
 	if err != nil {
@@ -192,7 +311,7 @@ func (s *AccomodationService) FindPriceById(id uint64, ctx context.Context) (mod
 func (service *AccomodationService) FindAvailableTermById(id uint64, ctx context.Context) (model.AvailableTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "findAvailableTermByIdService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	availableTerm, err := service.Repo.FindAvailableTermById(id, ctx)
 
 	if err != nil {
@@ -206,7 +325,7 @@ func (service *AccomodationService) FindAvailableTermById(id uint64, ctx context
 func (service *AccomodationService) FindReservedTermById(id uint64, ctx context.Context) (model.ReservedTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "findReservedTermByIdService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	reservedTerm, err := service.Repo.FindReservedTermById(id, ctx)
 
 	if err != nil {
@@ -220,7 +339,7 @@ func (service *AccomodationService) FindReservedTermById(id uint64, ctx context.
 func (service *AccomodationService) FindAvailableTerms(accommodationId uint, ctx context.Context) ([]model.AvailableTerm, error) {
 	span := tracer.StartSpanFromContext(ctx, "findAvailableTermsService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	_, err := service.Repo.FindAccomodationById(accommodationId, ctx)
 
@@ -229,73 +348,177 @@ func (service *AccomodationService) FindAvailableTerms(accommodationId uint, ctx
 		return []model.AvailableTerm{}, errors.New("accommodation with given id does not exist")
 	}
 
-	var availableTerms = service.Repo.FindAvailableTermAfter(accommodationId, time.Now(), ctx)
+	var availableTerms = service.Repo.FindAvailableTermAfter(accommodationId, service.Clock.Now(), ctx)
 
 	return availableTerms, nil
 }
 
-func (service *AccomodationService) CalculatePrice(accommodation model.Accomodation, searchAccomodationDTO model.SearchAccomodationDTO) (float32, int) {
-
-	prices := service.Repo.FindPricesForAccomodation(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate)
-	var basePrice float32 = 0
-	for _, price := range prices {
-		if price.PriceDuration == model.HOLIDAY || price.PriceDuration == model.WEEKEND {
-			basePrice = price.Value
-			break
-		} else {
+// CalculatePrice prices the stay and itemizes the discounts given for its
+// length and for how far ahead it is booked, so guests can see them next to
+// the undiscounted subtotal, followed by the fees and taxes of the
+// accomodation.
+func (service *AccomodationService) CalculatePrice(accommodation model.Accomodation, searchAccomodationDTO model.SearchAccomodationDTO, ctx context.Context) model.PriceBreakdownDTO {
+
+	// Every night is priced the way the calendar shows it, so a stay over a
+	// weekend or a holiday costs the sum of its nightly prices.
+	prices := service.Repo.FindPricesForAccomodation(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx)
+	nights := nightsBetween(searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate)
+	basePrice := model.NewMoney(0, accommodation.CurrencyCode())
+	nightsTotal := model.NewMoney(0, accommodation.CurrencyCode())
+	priced := false
+	for night := searchAccomodationDTO.StartDate; night.Before(searchAccomodationDTO.EndDate); night = night.AddDate(0, 0, 1) {
+		price, found := priceForDay(prices, night)
+		if !found {
+			continue
+		}
+		if !priced {
 			basePrice = price.Value
+			priced = true
 		}
+		nightsTotal = nightsTotal.Add(price.Value)
 	}
 
-	var totalPrice float32 = 0
-	if accommodation.PriceType == model.PER_GUEST {
-		totalPrice = float32(searchAccomodationDTO.NumberOfGuests) * basePrice * float32(searchAccomodationDTO.EndDate.Sub(searchAccomodationDTO.StartDate).Hours()/24)
-	} else {
-		totalPrice = basePrice
+	priceBreakdown := model.PriceBreakdownDTO{BasePrice: basePrice, Nights: nights, NightsTotal: nightsTotal}
+	priceBreakdown.ExtraGuestFee = model.NewMoney(0, basePrice.Currency)
+	priceBreakdown.ExtraGuestsTotal = model.NewMoney(0, basePrice.Currency)
+	switch accommodation.PriceType {
+	case model.PER_GUEST:
+		priceBreakdown.Subtotal = nightsTotal.Times(int64(searchAccomodationDTO.NumberOfGuests))
+	case model.PER_OCCUPANCY:
+		if searchAccomodationDTO.NumberOfGuests > accommodation.BaseOccupancy {
+			priceBreakdown.ExtraGuests = searchAccomodationDTO.NumberOfGuests - accommodation.BaseOccupancy
+		}
+		priceBreakdown.ExtraGuestFee = priceBreakdown.ExtraGuestFee.Add(accommodation.ExtraGuestFee)
+		priceBreakdown.ExtraGuestsTotal = priceBreakdown.ExtraGuestFee.Times(int64(priceBreakdown.ExtraGuests) * int64(nights))
+		priceBreakdown.Subtotal = nightsTotal.Add(priceBreakdown.ExtraGuestsTotal)
+	default:
+		priceBreakdown.Subtotal = nightsTotal
+	}
+
+	daysBeforeCheckIn := nightsBetween(accommodation.DateOf(service.Clock.Now()), searchAccomodationDTO.StartDate)
+	priceBreakdown.Discounts = applyDiscounts(service.Repo.FindDiscountRulesForAccomodation(accommodation.ID, ctx), nights, daysBeforeCheckIn, priceBreakdown.Subtotal)
+	discountedSubtotal := priceBreakdown.Subtotal
+	for _, discount := range priceBreakdown.Discounts {
+		discountedSubtotal = discountedSubtotal.Sub(discount.Amount)
 	}
 
-	return basePrice, int(totalPrice)
+	fees := service.Repo.FindFeesForAccomodation(accommodation.ID, ctx)
+	priceBreakdown.Fees, priceBreakdown.FeesTotal = applyFees(fees, model.FEE, nights, searchAccomodationDTO.NumberOfGuests, discountedSubtotal)
+	priceBreakdown.Taxes, priceBreakdown.TaxesTotal = applyFees(fees, model.TAX, nights, searchAccomodationDTO.NumberOfGuests, discountedSubtotal)
+	priceBreakdown.Total = discountedSubtotal.Add(priceBreakdown.FeesTotal).Add(priceBreakdown.TaxesTotal)
+
+	return priceBreakdown
 
 }
 
 func (service *AccomodationService) SearchAccomodations(searchAccomodationDTO model.SearchAccomodationDTO, ctx context.Context) []model.SearchAccomodationReturnDTO {
 	span := tracer.StartSpanFromContext(ctx, "searchAccomodationsService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
+	searchAccomodationDTO = toCalendarDates(searchAccomodationDTO)
 	accomodations := service.Repo.FindAccomodationByGuestsAndAddress(searchAccomodationDTO.NumberOfGuests, searchAccomodationDTO.Address, ctx)
 
 	var availableAccomodations []model.SearchAccomodationReturnDTO
 	for _, accommodation := range accomodations {
-		if service.Repo.IsAvailable(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx) == true {
-			if service.Repo.IsReserved(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx) == false {
-				basePrice, totalPrice := service.CalculatePrice(accommodation, searchAccomodationDTO)
-				accomodationDTO := accommodation.ToDTO()
-				accomodationDTO.Images = service.Repo.FindImagesForAccomodation(accommodation.ID)
-				var searchAccomodationReturnDTO model.SearchAccomodationReturnDTO
-				searchAccomodationReturnDTO.Accomodation = accomodationDTO
-				searchAccomodationReturnDTO.Price = basePrice
-				searchAccomodationReturnDTO.TotalPrice = totalPrice
-				searchAccomodationReturnDTO.StartDate = searchAccomodationDTO.StartDate
-				searchAccomodationReturnDTO.EndDate = searchAccomodationDTO.EndDate
-				searchAccomodationReturnDTO.NumberOfGuests = searchAccomodationDTO.NumberOfGuests
-				availableAccomodations = append(availableAccomodations, searchAccomodationReturnDTO)
+		if service.checkBookable(accommodation, searchAccomodationDTO, ctx) == nil {
+			availableAccomodation := service.toSearchAccomodationReturnDTO(accommodation, searchAccomodationDTO, ctx)
+			if err := service.addDisplayPrice(&availableAccomodation, searchAccomodationDTO.Currency, ctx); err != nil {
+				tracer.LogError(span, err)
 			}
+			availableAccomodations = append(availableAccomodations, availableAccomodation)
 		}
 	}
 	return availableAccomodations
 }
 
+// Quote prices a stay in the given accomodation, explaining why it can not be
+// booked when that is the case.
+func (service *AccomodationService) Quote(accomodationId uint, searchAccomodationDTO model.SearchAccomodationDTO, ctx context.Context) (model.SearchAccomodationReturnDTO, error) {
+	span := tracer.StartSpanFromContext(ctx, "quoteService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	searchAccomodationDTO = toCalendarDates(searchAccomodationDTO)
+	if err := validateDisplayCurrency(searchAccomodationDTO.Currency); err != nil {
+		tracer.LogError(span, err)
+		return model.SearchAccomodationReturnDTO{}, err
+	}
+
+	accommodation, err := service.Repo.FindAccomodationById(accomodationId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.SearchAccomodationReturnDTO{}, errors.New("accomodation with given id does not exist")
+	}
+
+	if searchAccomodationDTO.NumberOfGuests < accommodation.MinimimGuests || searchAccomodationDTO.NumberOfGuests > accommodation.MaximumGuests {
+		err := fmt.Errorf("accomodation hosts between %d and %d guests", accommodation.MinimimGuests, accommodation.MaximumGuests)
+		tracer.LogError(span, err)
+		return model.SearchAccomodationReturnDTO{}, err
+	}
+
+	if err := service.checkBookable(accommodation, searchAccomodationDTO, ctx); err != nil {
+		tracer.LogError(span, err)
+		return model.SearchAccomodationReturnDTO{}, err
+	}
+
+	quote := service.toSearchAccomodationReturnDTO(accommodation, searchAccomodationDTO, ctx)
+	if err := service.addDisplayPrice(&quote, searchAccomodationDTO.Currency, ctx); err != nil {
+		tracer.LogError(span, err)
+		return model.SearchAccomodationReturnDTO{}, err
+	}
+	return quote, nil
+}
+
+// toCalendarDates keeps only the calendar dates the guest asked for, so that
+// "June 1 to June 5" means the same nights whatever zone the client is in.
+func toCalendarDates(searchAccomodationDTO model.SearchAccomodationDTO) model.SearchAccomodationDTO {
+	searchAccomodationDTO.StartDate = model.CalendarDate(searchAccomodationDTO.StartDate)
+	searchAccomodationDTO.EndDate = model.CalendarDate(searchAccomodationDTO.EndDate)
+	return searchAccomodationDTO
+}
+
+func (service *AccomodationService) checkBookable(accommodation model.Accomodation, searchAccomodationDTO model.SearchAccomodationDTO, ctx context.Context) error {
+	if err := checkCheckIn(accommodation, searchAccomodationDTO.StartDate); err != nil {
+		return err
+	}
+	if err := service.checkStayLength(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx); err != nil {
+		return err
+	}
+	if !service.isAvailable(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx) {
+		return errors.New("accomodation is not available for given dates")
+	}
+	reservedFrom, reservedTo := reservationWindow(accommodation, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate)
+	if service.Repo.IsReserved(accommodation.ID, reservedFrom, reservedTo, ctx) ||
+		service.Repo.IsBlocked(accommodation.ID, searchAccomodationDTO.StartDate, searchAccomodationDTO.EndDate, ctx) {
+		return errors.New("accomodation is already reserved for given dates")
+	}
+	return nil
+}
+
+func (service *AccomodationService) toSearchAccomodationReturnDTO(accommodation model.Accomodation, searchAccomodationDTO model.SearchAccomodationDTO, ctx context.Context) model.SearchAccomodationReturnDTO {
+	priceBreakdown := service.CalculatePrice(accommodation, searchAccomodationDTO, ctx)
+	accomodationDTO := accommodation.ToDTO()
+	accomodationDTO.Images = service.Repo.FindImagesForAccomodation(accommodation.ID, ctx)
+	var searchAccomodationReturnDTO model.SearchAccomodationReturnDTO
+	searchAccomodationReturnDTO.Accomodation = accomodationDTO
+	searchAccomodationReturnDTO.Price = priceBreakdown.BasePrice
+	searchAccomodationReturnDTO.TotalPrice = priceBreakdown.Total
+	searchAccomodationReturnDTO.PriceBreakdown = priceBreakdown
+	searchAccomodationReturnDTO.StartDate = searchAccomodationDTO.StartDate
+	searchAccomodationReturnDTO.EndDate = searchAccomodationDTO.EndDate
+	searchAccomodationReturnDTO.NumberOfGuests = searchAccomodationDTO.NumberOfGuests
+	return searchAccomodationReturnDTO
+}
 
 func (service *AccomodationService) FindAccommodationsForHost(hostId uint, ctx context.Context) []model.AccomodationDTO {
 	span := tracer.StartSpanFromContext(ctx, "findAccomodationsForHostService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	accomodations := service.Repo.FindAccomodationsForHost(hostId, ctx)
 
 	var hostAccomodations []model.AccomodationDTO
 	for _, accommodation := range accomodations {
 		accomodationDTO := accommodation.ToDTO()
-		accomodationDTO.Images = service.Repo.FindImagesForAccomodation(accommodation.ID)
+		accomodationDTO.Images = service.Repo.FindImagesForAccomodation(accommodation.ID, ctx)
 		hostAccomodations = append(hostAccomodations, accomodationDTO)
 
 	}
@@ -305,7 +528,7 @@ func (service *AccomodationService) FindAccommodationsForHost(hostId uint, ctx c
 func (service *AccomodationService) GetAvailableTermsForAccomodation(accomodationId uint, ctx context.Context) []model.AvailableTermDTO {
 	span := tracer.StartSpanFromContext(ctx, "getAvailableTermsForAccomodationService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	availableTerms := service.Repo.GetAvailableTermsForAccomodation(accomodationId, ctx)
 
 	var availableTermsDTO []model.AvailableTermDTO
@@ -320,7 +543,7 @@ func (service *AccomodationService) GetAvailableTermsForAccomodation(accomodatio
 func (service *AccomodationService) GetPricesForAccomodation(accomodationId uint, ctx context.Context) []model.PriceDTO {
 	span := tracer.StartSpanFromContext(ctx, "getPricesForAccomodationService")
 	defer span.Finish()
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 	prices := service.Repo.GetPricesForAccomodation(accomodationId, ctx)
 
 	var pricesDTO []model.PriceDTO
@@ -331,3 +554,35 @@ func (service *AccomodationService) GetPricesForAccomodation(accomodationId uint
 	}
 	return pricesDTO
 }
+
+// GetPriceHistoryForAccomodation lists every version of the prices of an
+// accomodation, oldest first. Given a moment, it lists only the versions that
+// were in effect then, such as when a reservation was made.
+func (service *AccomodationService) GetPriceHistoryForAccomodation(accomodationId uint, at *time.Time, ctx context.Context) []model.PriceDTO {
+	span := tracer.StartSpanFromContext(ctx, "getPriceHistoryForAccomodationService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+	prices := service.Repo.GetPriceHistoryForAccomodation(accomodationId, ctx)
+
+	pricesDTO := []model.PriceDTO{}
+	for _, price := range prices {
+		if at != nil && !price.ActiveAt(*at) {
+			continue
+		}
+		pricesDTO = append(pricesDTO, price.ToDTO())
+	}
+	return pricesDTO
+}
+
+func (service *AccomodationService) findOwnedAccomodation(accomodationId uint, hostId uint, ctx context.Context) (model.Accomodation, error) {
+	accomodation, err := service.Repo.FindAccomodationById(accomodationId, ctx)
+	if err != nil {
+		return model.Accomodation{}, errors.New("Given accommodation does not exist.")
+	}
+
+	if hostId != accomodation.UserId {
+		return model.Accomodation{}, errors.New("You don't have access to this entity.")
+	}
+
+	return accomodation, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// ServiceTokenHeader carries the shared token other services of windbnb send
+// when they change reserved terms on behalf of a reservation.
+const ServiceTokenHeader = "X-Service-Token"
+
+// AuthorizeServiceCaller checks that a request comes from another windbnb
+// service rather than from a user. Without a configured token no caller is
+// trusted.
+func (service *AccomodationService) AuthorizeServiceCaller(token string) error {
+	if service.ServiceToken == "" {
+		return errors.New("service callers are not configured")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(service.ServiceToken)) != 1 {
+		return errors.New("caller is not an authorized service")
+	}
+	return nil
+}
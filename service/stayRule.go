@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func (service *AccomodationService) CreateStayRule(stayRule model.StayRule, hostId uint, ctx context.Context) (model.StayRule, error) {
+	span := tracer.StartSpanFromContext(ctx, "createStayRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	_, err := service.findOwnedAccomodation(stayRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return model.StayRule{}, err
+	}
+
+	if err := validateStayRule(stayRule); err != nil {
+		tracer.LogError(span, err)
+		return model.StayRule{}, err
+	}
+
+	return service.Repo.SaveStayRule(stayRule, ctx), nil
+}
+
+func (service *AccomodationService) DeleteStayRule(id uint64, hostId uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteStayRuleService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	stayRule, err := service.Repo.FindStayRuleById(id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return errors.New("stay rule with given id does not exist")
+	}
+
+	_, err = service.findOwnedAccomodation(stayRule.AccomodationID, hostId, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return err
+	}
+
+	return service.Repo.DeleteStayRule(id, ctx)
+}
+
+func (service *AccomodationService) GetStayRulesForAccomodation(accomodationId uint, ctx context.Context) []model.StayRuleDTO {
+	span := tracer.StartSpanFromContext(ctx, "getStayRulesForAccomodationService")
+	defer span.Finish()
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	stayRulesDTO := []model.StayRuleDTO{}
+	for _, stayRule := range service.Repo.FindStayRulesForAccomodation(accomodationId, ctx) {
+		stayRulesDTO = append(stayRulesDTO, stayRule.ToDTO())
+	}
+	return stayRulesDTO
+}
+
+// checkStayLength returns an error when the stay breaks the stay rule that
+// applies to its check-in date.
+func (service *AccomodationService) checkStayLength(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) error {
+	if !endDate.After(startDate) {
+		return errors.New("stay has to end after it starts")
+	}
+
+	stayRule, found := stayRuleFor(service.Repo.FindStayRulesForAccomodation(accomodationId, ctx), startDate)
+	if !found {
+		return nil
+	}
+
+	nights := nightsBetween(startDate, endDate)
+	if stayRule.MinimumNights > 0 && nights < int(stayRule.MinimumNights) {
+		return fmt.Errorf("minimum stay for these dates is %d nights", stayRule.MinimumNights)
+	}
+	if stayRule.MaximumNights > 0 && nights > int(stayRule.MaximumNights) {
+		return fmt.Errorf("maximum stay for these dates is %d nights", stayRule.MaximumNights)
+	}
+	return nil
+}
+
+// stayRuleFor picks the rule whose dates contain the check-in date and falls
+// back to the rule without dates.
+func stayRuleFor(stayRules []model.StayRule, checkIn time.Time) (model.StayRule, bool) {
+	var defaultRule *model.StayRule
+	for i := range stayRules {
+		stayRule := &stayRules[i]
+		if stayRule.StartDate.IsZero() && stayRule.EndDate.IsZero() {
+			if defaultRule == nil {
+				defaultRule = stayRule
+			}
+			continue
+		}
+		if !checkIn.Before(stayRule.StartDate) && !checkIn.After(stayRule.EndDate) {
+			return *stayRule, true
+		}
+	}
+
+	if defaultRule != nil {
+		return *defaultRule, true
+	}
+	return model.StayRule{}, false
+}
+
+// nightsBetween counts the nights between two calendar dates, ignoring the
+// time of day.
+func nightsBetween(startDate time.Time, endDate time.Time) int {
+	return int(model.CalendarDate(endDate).Sub(model.CalendarDate(startDate)).Hours() / 24)
+}
+
+func validateStayRule(stayRule model.StayRule) error {
+	if stayRule.MinimumNights == 0 && stayRule.MaximumNights == 0 {
+		return errors.New("stay rule needs a minimum or a maximum number of nights")
+	}
+	if stayRule.MaximumNights > 0 && stayRule.MaximumNights < stayRule.MinimumNights {
+		return errors.New("maximum number of nights can not be lower than the minimum")
+	}
+	if stayRule.StartDate.IsZero() != stayRule.EndDate.IsZero() {
+		return errors.New("stay rule needs both a start and an end date, or neither")
+	}
+	if stayRule.EndDate.Before(stayRule.StartDate) {
+		return errors.New("stay rule can not end before it starts")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/auth"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+func fakeAuthenticator(users map[string]model.UserResponseDTO) auth.Authenticator {
+	return func(token string, ctx context.Context) (model.UserResponseDTO, error) {
+		user, found := users[token]
+		if !found {
+			return model.UserResponseDTO{}, errors.New("invalid token")
+		}
+		return user, nil
+	}
+}
+
+var testUsers = map[string]model.UserResponseDTO{
+	"Bearer host":  {Id: 1, Role: model.HOST},
+	"Bearer guest": {Id: 2, Role: model.GUEST},
+}
+
+// serveAuthenticated runs a request with the given Authorization header
+// through the middleware and reports the user the handler saw, if it ran.
+func serveAuthenticated(middleware func(http.HandlerFunc) http.HandlerFunc, token string) (*httptest.ResponseRecorder, *model.UserResponseDTO) {
+	var seenUser *model.UserResponseDTO
+	handler := middleware(func(w http.ResponseWriter, r *http.Request) {
+		user, found := auth.UserFrom(r.Context())
+		if found {
+			seenUser = &user
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/accomodation/price", nil)
+	if token != "" {
+		request.Header.Set("Authorization", token)
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	return recorder, seenUser
+}
+
+func errorMessage(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	var errorResponse model.ErrorResponse
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&errorResponse))
+	return errorResponse.Message
+}
+
+func TestRequire_PutsUserIntoContext(t *testing.T) {
+	recorder, user := serveAuthenticated(auth.Require(fakeAuthenticator(testUsers), model.HOST), "Bearer host")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, &model.UserResponseDTO{Id: 1, Role: model.HOST}, user)
+}
+
+func TestRequire_MissingToken(t *testing.T) {
+	recorder, user := serveAuthenticated(auth.Require(fakeAuthenticator(testUsers), model.HOST), "")
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "missing authorization token", errorMessage(t, recorder))
+	assert.Nil(t, user)
+}
+
+func TestRequire_InvalidToken(t *testing.T) {
+	recorder, user := serveAuthenticated(auth.Require(fakeAuthenticator(testUsers), model.HOST), "Bearer forged")
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "invalid token", errorMessage(t, recorder))
+	assert.Nil(t, user)
+}
+
+func TestRequire_WrongRole(t *testing.T) {
+	recorder, user := serveAuthenticated(auth.Require(fakeAuthenticator(testUsers), model.HOST), "Bearer guest")
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "user is not a host", errorMessage(t, recorder))
+	assert.Nil(t, user)
+}
+
+func TestRequire_AnyOfSeveralRoles(t *testing.T) {
+	middleware := auth.Require(fakeAuthenticator(testUsers), model.HOST, model.GUEST)
+
+	recorder, user := serveAuthenticated(middleware, "Bearer guest")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, uint(2), user.Id)
+}
+
+func TestRequire_WithoutRoles(t *testing.T) {
+	recorder, user := serveAuthenticated(auth.Require(fakeAuthenticator(testUsers)), "Bearer guest")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, model.GUEST, user.Role)
+}
+
+func TestRequireService(t *testing.T) {
+	called := false
+	handler := auth.RequireService("X-Service-Token", func(token string) error {
+		if token != "secret" {
+			return errors.New("caller is not an authorized service")
+		}
+		return nil
+	})(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/accomodation/reservedTerm", nil)
+	request.Header.Set("Authorization", "Bearer host")
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.False(t, called)
+
+	request.Header.Set("X-Service-Token", "secret")
+	recorder = httptest.NewRecorder()
+	handler(recorder, request)
+
+	assert.True(t, called)
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func TestAvailabilityRuleAllows(t *testing.T) {
+	availabilityRule := model.AvailabilityRule{
+		Weekdays:       "FR,SA,SU",
+		Months:         "6,7,8",
+		StartDate:      date(2023, 1, 1),
+		ExceptionDates: "2023-06-10",
+	}
+
+	assert.True(t, availabilityRule.Allows(date(2023, 6, 9)))
+	assert.False(t, availabilityRule.Allows(date(2023, 6, 10)))
+	assert.True(t, availabilityRule.Allows(date(2023, 6, 11)))
+	assert.False(t, availabilityRule.Allows(date(2023, 6, 12)))
+	assert.False(t, availabilityRule.Allows(date(2023, 9, 1)))
+	assert.False(t, availabilityRule.Allows(date(2022, 6, 10)))
+	assert.True(t, availabilityRule.Allows(date(2030, 8, 2)))
+}
+
+func TestCreateAvailabilityRule_InvalidWeekday(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1}, nil
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	availabilityRule, err := accommodationService.CreateAvailabilityRule(model.AvailabilityRule{AccomodationID: 1, Weekdays: "SA,XX"}, 1, context.Background())
+
+	assert.Empty(t, availabilityRule)
+	assert.EqualError(t, err, "weekday XX does not exist, use MO, TU, WE, TH, FR, SA or SU")
+}
+
+func TestCreateAvailabilityRule_UserDoesNotHaveAccess(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1}, nil
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	availabilityRule, err := accommodationService.CreateAvailabilityRule(model.AvailabilityRule{AccomodationID: 1, Weekdays: "SA"}, 2, context.Background())
+
+	assert.Empty(t, availabilityRule)
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestSearchAccomodations_CombinesRulesAndTerms(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByGuestsAndAddressFn: func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+			return []model.Accomodation{{Model: gorm.Model{ID: 1}, PriceType: model.PER_ACCOMODATION_UNIT}}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindAvailabilityRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+			return []model.AvailabilityRule{{AccomodationID: 1, Weekdays: "FR,SA,SU"}}
+		},
+		FindAvailableTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+			return []model.AvailableTerm{{StartDate: date(2023, 6, 5), EndDate: date(2023, 6, 6), AccomodationID: 1}}
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	// Friday to Monday is covered by the weekend rule, Monday night by the term.
+	covered := accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 2), EndDate: date(2023, 6, 6)}, context.Background())
+	// Tuesday night is covered by neither.
+	uncovered := accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 2), EndDate: date(2023, 6, 7)}, context.Background())
+
+	assert.Len(t, covered, 1)
+	assert.Empty(t, uncovered)
+}
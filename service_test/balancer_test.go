@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/client"
+)
+
+func instanceUrls(t *testing.T, rawUrls string) []*url.URL {
+	urls, err := client.ParseEndpoints(rawUrls)
+	assert.NoError(t, err)
+	return urls
+}
+
+func pickHosts(balancer *client.Balancer, picks int) []string {
+	hosts := []string{}
+	for i := 0; i < picks; i++ {
+		endpoint, done := balancer.Pick()
+		hosts = append(hosts, endpoint.Host)
+		done(false)
+	}
+	return hosts
+}
+
+func TestParseEndpoints(t *testing.T) {
+	urls, err := client.ParseEndpoints(" http://user-1:8081, https://user-2 ,")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://user-1:8081", urls[0].String())
+	assert.Equal(t, "https://user-2", urls[1].String())
+
+	_, err = client.ParseEndpoints("nginx:8000")
+	assert.EqualError(t, err, `endpoint "nginx:8000" has to be an absolute http or https url`)
+
+	_, err = client.ParseEndpoints(" , ")
+	assert.EqualError(t, err, "no endpoints given")
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	balancer := client.NewBalancer(instanceUrls(t, "http://a,http://b,http://c"), client.RoundRobin)
+
+	assert.Equal(t, []string{"a", "b", "c", "a"}, pickHosts(balancer, 4))
+}
+
+func TestBalancer_LeastConnections(t *testing.T) {
+	balancer := client.NewBalancer(instanceUrls(t, "http://a,http://b"), client.LeastConnections)
+
+	first, _ := balancer.Pick()
+	second, doneSecond := balancer.Pick()
+	assert.Equal(t, "a", first.Host)
+	assert.Equal(t, "b", second.Host)
+
+	doneSecond(false)
+	assert.Equal(t, []string{"b", "b"}, pickHosts(balancer, 2), "a is still busy")
+}
+
+func TestBalancer_EjectsFailingInstance(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	balancer := client.NewBalancer(instanceUrls(t, "http://a,http://b"), client.RoundRobin)
+	balancer.EjectAfter = 2
+	balancer.EjectFor = time.Minute
+	balancer.Clock = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		endpoint, done := balancer.Pick()
+		done(endpoint.Host == "a")
+	}
+
+	assert.Equal(t, []string{"b", "b", "b"}, pickHosts(balancer, 3))
+
+	now = now.Add(time.Minute)
+	assert.ElementsMatch(t, []string{"a", "b"}, pickHosts(balancer, 2))
+}
+
+func TestBalancer_FallsBackToAllInstances(t *testing.T) {
+	balancer := client.NewBalancer(instanceUrls(t, "http://a"), client.RoundRobin)
+	balancer.EjectAfter = 1
+
+	endpoint, done := balancer.Pick()
+	done(true)
+
+	assert.Equal(t, []string{endpoint.Host}, pickHosts(balancer, 1))
+}
+
+func TestBalancer_HealthChecks(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+	}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+	healthyUrl, _ := url.Parse(healthy.URL)
+	balancer := client.NewBalancer(instanceUrls(t, healthy.URL+","+unhealthy.URL), client.RoundRobin)
+
+	balancer.CheckHealth("/health", context.Background())
+
+	assert.Equal(t, []string{healthyUrl.Host, healthyUrl.Host}, pickHosts(balancer, 2))
+}
+
+func TestBalancer_SetEndpointsKeepsState(t *testing.T) {
+	balancer := client.NewBalancer(instanceUrls(t, "http://a,http://b"), client.LeastConnections)
+	busy, _ := balancer.Pick()
+	assert.Equal(t, "a", busy.Host)
+
+	balancer.SetEndpoints(instanceUrls(t, "http://c,http://a"))
+
+	assert.Equal(t, []string{"c", "c"}, pickHosts(balancer, 2))
+}
+
+type fakeSRVResolver struct {
+	records []*net.SRV
+	err     error
+}
+
+func (resolver fakeSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return name, resolver.records, resolver.err
+}
+
+func TestSRVDiscovery(t *testing.T) {
+	discovery := client.NewSRVDiscovery("_http._tcp.user-service")
+	discovery.Resolver = fakeSRVResolver{records: []*net.SRV{
+		{Target: "user-1.windbnb.local.", Port: 8081, Priority: 10},
+		{Target: "backup.windbnb.local.", Port: 8081, Priority: 20},
+		{Target: "user-2.windbnb.local.", Port: 8082, Priority: 10},
+	}}
+
+	urls, err := discovery.Discover(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*url.URL{
+		{Scheme: "http", Host: "user-1.windbnb.local:8081"},
+		{Scheme: "http", Host: "user-2.windbnb.local:8082"},
+	}, urls)
+
+	discovery.Resolver = fakeSRVResolver{err: errors.New("no such host")}
+	_, err = discovery.Discover(context.Background())
+	assert.EqualError(t, err, "no such host")
+}
@@ -0,0 +1,399 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+const externalCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Listing//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:booking-1@example.com\r\n" +
+	"DTSTAMP:20230501T120000Z\r\n" +
+	"DTSTART;VALUE=DATE:20230601\r\n" +
+	"DTEND;VALUE=DATE:20230605\r\n" +
+	"SUMMARY:Not available\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:booking-1@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20230601\r\n" +
+	"DTEND;VALUE=DATE:20230605\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:booking-2@exam\r\n" +
+	" ple.com\r\n" +
+	"DTSTART;TZID=Europe/Belgrade:20230710T140000\r\n" +
+	"DURATION:P2DT20H\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20230801\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+// blockedTermStore keeps blocked terms in memory so tests can observe what a
+// sync stored.
+type blockedTermStore struct {
+	nextId  uint
+	terms   map[uint]model.BlockedTerm
+	imports map[uint]model.CalendarImport
+}
+
+func newBlockedTermStore(mockRepo *MockRepo) *blockedTermStore {
+	store := &blockedTermStore{nextId: 1, terms: map[uint]model.BlockedTerm{}, imports: map[uint]model.CalendarImport{}}
+
+	mockRepo.FindAccomodationByIdFn = func(id uint, ctx context.Context) (model.Accomodation, error) {
+		return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+	}
+	mockRepo.SaveCalendarImportFn = func(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+		calendarImport.ID = store.nextId
+		store.nextId++
+		store.imports[calendarImport.ID] = calendarImport
+		return calendarImport
+	}
+	mockRepo.UpdateCalendarImportFn = func(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+		store.imports[calendarImport.ID] = calendarImport
+		return calendarImport
+	}
+	mockRepo.FindCalendarImportsForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.CalendarImport {
+		calendarImports := []model.CalendarImport{}
+		for _, calendarImport := range store.imports {
+			calendarImports = append(calendarImports, calendarImport)
+		}
+		return calendarImports
+	}
+	mockRepo.FindUrlCalendarImportsFn = func(ctx context.Context) []model.CalendarImport {
+		calendarImports := []model.CalendarImport{}
+		for _, calendarImport := range store.imports {
+			if calendarImport.Url != "" {
+				calendarImports = append(calendarImports, calendarImport)
+			}
+		}
+		return calendarImports
+	}
+	mockRepo.SaveBlockedTermFn = func(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+		blockedTerm.ID = store.nextId
+		store.nextId++
+		store.terms[blockedTerm.ID] = blockedTerm
+		return blockedTerm
+	}
+	mockRepo.UpdateBlockedTermFn = func(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+		store.terms[blockedTerm.ID] = blockedTerm
+		return blockedTerm
+	}
+	mockRepo.DeleteBlockedTermFn = func(id uint, ctx context.Context) error {
+		delete(store.terms, id)
+		return nil
+	}
+	mockRepo.FindBlockedTermsForCalendarImportFn = func(calendarImportId uint, ctx context.Context) []model.BlockedTerm {
+		blockedTerms := []model.BlockedTerm{}
+		for _, blockedTerm := range store.terms {
+			if blockedTerm.CalendarImportID == calendarImportId {
+				blockedTerms = append(blockedTerms, blockedTerm)
+			}
+		}
+		return blockedTerms
+	}
+	return store
+}
+
+func (store *blockedTermStore) termsByUID() map[string]model.BlockedTerm {
+	terms := map[string]model.BlockedTerm{}
+	for _, blockedTerm := range store.terms {
+		terms[blockedTerm.UID] = blockedTerm
+	}
+	return terms
+}
+
+func TestImportCalendarFromUrl_UserDoesNotHaveAccess(t *testing.T) {
+	mockRepo := &MockRepo{}
+	newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendarImport, err := accommodationService.ImportCalendarFromUrl(1, 2, "https://example.com/calendar.ics", context.Background())
+
+	assert.Empty(t, calendarImport)
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestImportCalendarFromUrl_InvalidUrl(t *testing.T) {
+	mockRepo := &MockRepo{}
+	newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendarImport, err := accommodationService.ImportCalendarFromUrl(1, 1, "ftp://example.com/calendar.ics", context.Background())
+
+	assert.Empty(t, calendarImport)
+	assert.EqualError(t, err, "calendar url has to use http, https or webcal")
+}
+
+func TestImportCalendarFromUrl_Successfull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		w.Write([]byte(externalCalendar))
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo:       mockRepo,
+		HttpClient: server.Client(),
+	}
+
+	calendarImport, err := accommodationService.ImportCalendarFromUrl(1, 1, server.URL, context.Background())
+
+	belgrade, _ := time.LoadLocation("Europe/Belgrade")
+	terms := store.termsByUID()
+	assert.NoError(t, err)
+	assert.Empty(t, calendarImport.LastError)
+	assert.NotNil(t, calendarImport.LastSyncedAt)
+	assert.Len(t, terms, 2)
+	assert.Equal(t, date(2023, 6, 1), terms["booking-1@example.com"].StartDate)
+	assert.Equal(t, date(2023, 6, 5), terms["booking-1@example.com"].EndDate)
+	assert.True(t, time.Date(2023, 7, 10, 14, 0, 0, 0, belgrade).Equal(terms["booking-2@example.com"].StartDate))
+	assert.True(t, time.Date(2023, 7, 13, 10, 0, 0, 0, belgrade).Equal(terms["booking-2@example.com"].EndDate))
+	assert.Equal(t, uint(1), terms["booking-1@example.com"].AccomodationID)
+}
+
+func TestImportCalendarFromUrl_RefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(externalCalendar))
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendarImport, err := accommodationService.ImportCalendarFromUrl(1, 1, server.URL, context.Background())
+
+	assert.NoError(t, err)
+	assert.Contains(t, calendarImport.LastError, "calendar address 127.0.0.1 is not public")
+	assert.Nil(t, calendarImport.LastSyncedAt)
+	assert.Empty(t, store.termsByUID())
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"224.0.0.1", false},
+		{"239.255.255.250", false},
+		{"255.255.255.255", false},
+		{"240.0.0.1", false},
+		{"198.18.0.1", false},
+		{"::", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"ff02::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:93.184.216.34", true},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b:1::a00:1", false},
+		{"2002:a00:1::", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.public, service.IsPublicAddress(net.ParseIP(test.address)), test.address)
+	}
+}
+
+func TestSyncCalendarImports_UpdatesAndRemovesEvents(t *testing.T) {
+	calendar := externalCalendar
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(calendar))
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo:       mockRepo,
+		HttpClient: server.Client(),
+	}
+
+	_, err := accommodationService.ImportCalendarFromUrl(1, 1, server.URL, context.Background())
+	assert.NoError(t, err)
+	firstId := store.termsByUID()["booking-1@example.com"].ID
+
+	calendar = strings.Replace(externalCalendar, "DTEND;VALUE=DATE:20230605", "DTEND;VALUE=DATE:20230607", 1)
+	calendar = strings.Replace(calendar, "UID:booking-2@exam\r\n ple.com", "UID:booking-3@example.com", 1)
+	accommodationService.SyncCalendarImports(context.Background())
+
+	terms := store.termsByUID()
+	assert.Len(t, terms, 2)
+	assert.Equal(t, firstId, terms["booking-1@example.com"].ID)
+	assert.Equal(t, date(2023, 6, 7), terms["booking-1@example.com"].EndDate)
+	assert.Contains(t, terms, "booking-3@example.com")
+	assert.NotContains(t, terms, "booking-2@example.com")
+}
+
+func TestSyncCalendarImports_RecordsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+	store.imports[1] = model.CalendarImport{Model: gorm.Model{ID: 1}, AccomodationID: 1, Url: server.URL}
+	store.terms[2] = model.BlockedTerm{Model: gorm.Model{ID: 2}, CalendarImportID: 1, UID: "kept@example.com"}
+
+	accommodationService := service.AccomodationService{
+		Repo:       mockRepo,
+		HttpClient: server.Client(),
+	}
+
+	accommodationService.SyncCalendarImports(context.Background())
+
+	assert.Equal(t, "calendar responded with status 500", store.imports[1].LastError)
+	assert.Contains(t, store.termsByUID(), "kept@example.com")
+}
+
+func TestImportCalendarFile_DeduplicatesByUID(t *testing.T) {
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	first, err := accommodationService.ImportCalendarFile(1, 1, strings.NewReader(externalCalendar), context.Background())
+	assert.NoError(t, err)
+	second, err := accommodationService.ImportCalendarFile(1, 1, strings.NewReader(externalCalendar), context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.Empty(t, second.Url)
+	assert.Len(t, store.terms, 2)
+}
+
+func TestImportCalendarFile_IgnoresAlarmProperties(t *testing.T) {
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:booking-3@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20230901\r\n" +
+		"DTEND;VALUE=DATE:20230904\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"UID:alarm@example.com\r\n" +
+		"DESCRIPTION:Check-in tomorrow\r\n" +
+		"TRIGGER:-P1D\r\n" +
+		"DTSTART;VALUE=DATE:20230831\r\n" +
+		"DTEND;VALUE=DATE:20230831\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	mockRepo := &MockRepo{}
+	store := newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	_, err := accommodationService.ImportCalendarFile(1, 1, strings.NewReader(calendar), context.Background())
+
+	terms := store.termsByUID()
+	assert.NoError(t, err)
+	assert.Len(t, terms, 1)
+	assert.Equal(t, date(2023, 9, 1), terms["booking-3@example.com"].StartDate)
+	assert.Equal(t, date(2023, 9, 4), terms["booking-3@example.com"].EndDate)
+}
+
+func TestImportCalendarFile_InvalidCalendar(t *testing.T) {
+	mockRepo := &MockRepo{}
+	newBlockedTermStore(mockRepo)
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendarImport, err := accommodationService.ImportCalendarFile(1, 1, strings.NewReader("hello"), context.Background())
+
+	assert.Empty(t, calendarImport)
+	assert.Error(t, err)
+}
+
+func TestSearchAccomodations_SkipsBlockedAccomodations(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByGuestsAndAddressFn: func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+			return []model.Accomodation{
+				{Model: gorm.Model{ID: 1}, PriceType: model.PER_ACCOMODATION_UNIT},
+				{Model: gorm.Model{ID: 2}, PriceType: model.PER_ACCOMODATION_UNIT},
+			}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return true
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return accomodationId == 1
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	results := accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 5)}, context.Background())
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, uint(2), results[0].Accomodation.Id)
+}
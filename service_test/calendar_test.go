@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/handler"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGetCalendar_AccomodationDoesNotExist(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{}, errors.New("there is no accomodation with id 1")
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendar, err := accommodationService.GetCalendar(1, date(2023, 6, 1), date(2023, 6, 5), context.Background())
+
+	assert.Empty(t, calendar)
+	assert.EqualError(t, err, "accomodation with given id does not exist")
+}
+
+func TestGetCalendar_EndBeforeStart(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{},
+	}
+
+	calendar, err := accommodationService.GetCalendar(1, date(2023, 6, 5), date(2023, 6, 1), context.Background())
+
+	assert.Empty(t, calendar)
+	assert.EqualError(t, err, "calendar end date can not be before start date")
+}
+
+func TestGetCalendar_Successfull(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}}, nil
+		},
+		FindAvailableTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+			return []model.AvailableTerm{
+				{StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4), AccomodationID: 1},
+			}
+		},
+		FindReservedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+			return []model.ReservedTerm{
+				{StartDate: date(2023, 6, 2), EndDate: date(2023, 6, 3), AccomodationID: 1},
+			}
+		},
+		FindBlockedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+			return []model.BlockedTerm{
+				{StartDate: date(2023, 6, 3), EndDate: date(2023, 6, 4), AccomodationID: 1, UID: "booking@example.com"},
+			}
+		},
+		FindAvailabilityRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+			return []model.AvailabilityRule{}
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{
+				{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true},
+				{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(4000), PriceDuration: model.WEEKEND, AccomodationID: 1, Active: true},
+			}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	// 2023-06-01 is a Thursday, so the range covers Thursday to Sunday. The
+	// last day is not covered by any available term.
+	calendar, err := accommodationService.GetCalendar(1, date(2023, 6, 1), date(2023, 6, 4), context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), calendar.AccomodationID)
+	assert.Equal(t, []model.CalendarDayDTO{
+		{Date: date(2023, 6, 1), Status: model.AVAILABLE, Price: rsd(3000), PriceDuration: model.REGULAR},
+		{Date: date(2023, 6, 2), Status: model.RESERVED, Price: rsd(3000), PriceDuration: model.REGULAR},
+		{Date: date(2023, 6, 3), Status: model.BLOCKED, Price: rsd(4000), PriceDuration: model.WEEKEND},
+		{Date: date(2023, 6, 4), Status: model.BLOCKED, Price: rsd(4000), PriceDuration: model.WEEKEND},
+	}, calendar.Days)
+}
+
+func TestExportCalendar_InvalidToken(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, CalendarToken: "secret"}, nil
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendar, err := accommodationService.ExportCalendar(1, "guess", false, context.Background())
+
+	assert.Empty(t, calendar)
+	assert.EqualError(t, err, "invalid calendar token")
+}
+
+func TestExportCalendar_Successfull(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, Name: "Vila Marija, Novi Sad", CalendarToken: "secret"}, nil
+		},
+		GetReservedTermsForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.ReservedTerm {
+			return []model.ReservedTerm{
+				{Model: gorm.Model{ID: 7}, StartDate: time.Date(2023, 6, 2, 14, 0, 0, 0, time.UTC), EndDate: time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC), AccomodationID: 1},
+			}
+		},
+		GetAvailableTermsForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.AvailableTerm {
+			return []model.AvailableTerm{
+				{Model: gorm.Model{ID: 3}, StartDate: date(2023, 6, 1), EndDate: date(2023, 9, 1), AccomodationID: 1},
+			}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendar, err := accommodationService.ExportCalendar(1, "secret", true, context.Background())
+	content := string(calendar)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(content, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(content, "END:VCALENDAR\r\n"))
+	assert.Contains(t, content, "X-WR-CALNAME:Vila Marija\\, Novi Sad\r\n")
+	assert.Contains(t, content, "UID:reserved-term-7@windbnb\r\nDTSTAMP:")
+	assert.Contains(t, content, "DTSTART:20230602T140000Z\r\nDTEND:20230605T100000Z\r\nSUMMARY:Reserved\r\nTRANSP:OPAQUE\r\n")
+	assert.Contains(t, content, "UID:available-term-3@windbnb\r\n")
+	assert.Contains(t, content, "SUMMARY:Available\r\nTRANSP:TRANSPARENT\r\n")
+	for _, line := range strings.Split(content, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+}
+
+func TestQuote_MatchesNightlyCalendarPrices(t *testing.T) {
+	mockRepo := discountRepo()
+	mockRepo.FindPricesForAccomodationFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+		return []model.Price{
+			{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(1000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true},
+			{StartDate: date(2023, 6, 1), EndDate: date(2023, 7, 1), Value: rsd(1500), PriceDuration: model.WEEKEND, AccomodationID: 1, Active: true},
+		}
+	}
+	mockRepo.FindAvailableTermsBetweenFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+		return []model.AvailableTerm{}
+	}
+	mockRepo.FindReservedTermsBetweenFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+		return []model.ReservedTerm{}
+	}
+	mockRepo.FindBlockedTermsBetweenFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+		return []model.BlockedTerm{}
+	}
+	mockRepo.FindAvailabilityRulesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+		return []model.AvailabilityRule{}
+	}
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	// Thursday to Monday, so the Saturday and Sunday nights cost the weekend price.
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 5)}, context.Background())
+	calendar, _ := accommodationService.GetCalendar(1, date(2023, 6, 1), date(2023, 6, 4), context.Background())
+
+	nightlyPrices := model.NewMoney(0, model.DefaultCurrency)
+	for _, day := range calendar.Days {
+		nightlyPrices = nightlyPrices.Add(day.Price)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, rsd(5000), nightlyPrices)
+	assert.Equal(t, nightlyPrices, quote.PriceBreakdown.NightsTotal)
+	assert.Equal(t, rsd(1000), quote.PriceBreakdown.BasePrice)
+	assert.Equal(t, rsd(10000), quote.PriceBreakdown.Subtotal)
+	assert.Equal(t, rsd(10000), quote.TotalPrice)
+}
+
+func TestCreateAvailableTerm_RejectsUndecodableBody(t *testing.T) {
+	termHandler := &handler.Handler{
+		Service: &service.AccomodationService{
+			Repo: &MockRepo{
+				SaveAvailableTermFn: func(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+					t.Fatal("no term should be saved from a body that does not decode")
+					return availableTerm
+				},
+			},
+		},
+		Tracer: tracer.Global(),
+	}
+
+	recorder := httptest.NewRecorder()
+	termHandler.CreateAvailableTerm(recorder, httptest.NewRequest(http.MethodPost, "/api/accomodation/availableTerm", bytes.NewBufferString(`[{"startDate":"tomorrow"}]`)))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func checkInOutAccomodation() model.Accomodation {
+	return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1, MinimimGuests: 1, MaximumGuests: 4, PriceType: model.PER_ACCOMODATION_UNIT,
+		CheckInTime: "15:00", CheckOutTime: "11:00", CheckInDays: "FR,SA", TimeZone: "UTC"}
+}
+
+func TestSaveReservedTerm_NormalizesToCheckInAndCheckOut(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return checkInOutAccomodation(), nil
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		SaveReservedTermFn: func(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
+			return reservedTerm
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: date(2023, 6, 2), EndDate: date(2023, 6, 4), AccomodationID: 1}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 6, 2, 15, 0, 0, 0, time.UTC), reservedTerm.StartDate)
+	assert.Equal(t, time.Date(2023, 6, 4, 11, 0, 0, 0, time.UTC), reservedTerm.EndDate)
+}
+
+func TestSaveReservedTerm_CheckInDayNotAllowed(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return checkInOutAccomodation(), nil
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: date(2023, 6, 4), EndDate: date(2023, 6, 6), AccomodationID: 1}, context.Background())
+
+	assert.Empty(t, reservedTerm)
+	assert.EqualError(t, err, "check-in is not allowed on Sunday")
+}
+
+func TestUpdateCheckInOut_CheckOutAfterCheckIn(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return checkInOutAccomodation(), nil
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	accommodation, err := accommodationService.UpdateCheckInOut(1, model.CheckInOutDTO{CheckInTime: "10:00", CheckOutTime: "12:00"}, 1, context.Background())
+
+	assert.Empty(t, accommodation)
+	assert.EqualError(t, err, "check-out time can not be after check-in time")
+}
+
+func TestUpdateCheckInOut_Successfull(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return checkInOutAccomodation(), nil
+		},
+		UpdateAccommodationFn: func(accomodation model.Accomodation, ctx context.Context) model.Accomodation {
+			return accomodation
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	accommodation, err := accommodationService.UpdateCheckInOut(1, model.CheckInOutDTO{CheckInTime: "16:00", CheckOutTime: "09:30", CheckInDays: []string{"SA"}}, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "16:00", accommodation.CheckInTime)
+	assert.Equal(t, "09:30", accommodation.CheckOutTime)
+	assert.Equal(t, "SA", accommodation.CheckInDays)
+	assert.Equal(t, "UTC", accommodation.TimeZone, "time zone is kept when it is not sent")
+}
+
+func TestSearchAccomodations_SameDayTurnover(t *testing.T) {
+	var reservedFrom, reservedTo time.Time
+	mockRepo := &MockRepo{
+		FindAccomodationByGuestsAndAddressFn: func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+			return []model.Accomodation{checkInOutAccomodation()}
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return true
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			reservedFrom, reservedTo = startDate, endDate
+			return false
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	accomodations := accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 3), EndDate: date(2023, 6, 5)}, context.Background())
+
+	assert.Len(t, accomodations, 1)
+	assert.Equal(t, time.Date(2023, 6, 3, 15, 0, 0, 0, time.UTC), reservedFrom)
+	assert.Equal(t, time.Date(2023, 6, 5, 11, 0, 0, 0, time.UTC), reservedTo)
+}
+
+func TestGetCalendar_CheckOutDayIsFree(t *testing.T) {
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return checkInOutAccomodation(), nil
+		},
+		FindAvailableTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+			return []model.AvailableTerm{{StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 30), AccomodationID: 1}}
+		},
+		FindReservedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+			return []model.ReservedTerm{{StartDate: time.Date(2023, 6, 2, 15, 0, 0, 0, time.UTC), EndDate: time.Date(2023, 6, 4, 11, 0, 0, 0, time.UTC), AccomodationID: 1}}
+		},
+		FindBlockedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+			return []model.BlockedTerm{}
+		},
+		FindAvailabilityRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+			return []model.AvailabilityRule{}
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	calendar, err := accommodationService.GetCalendar(1, date(2023, 6, 1), date(2023, 6, 4), context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CalendarDayStatus{model.AVAILABLE, model.RESERVED, model.RESERVED, model.AVAILABLE},
+		[]model.CalendarDayStatus{calendar.Days[0].Status, calendar.Days[1].Status, calendar.Days[2].Status, calendar.Days[3].Status})
+}
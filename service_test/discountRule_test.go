@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func discountRepo() *MockRepo {
+	return &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1, MinimimGuests: 1, MaximumGuests: 4, PriceType: model.PER_GUEST}, nil
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return true
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(1000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{
+				{Model: gorm.Model{ID: 1}, AccomodationID: 1, MinimumNights: 7, Percentage: 10},
+				{Model: gorm.Model{ID: 2}, AccomodationID: 1, MinimumNights: 28, Percentage: 25},
+			}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+	}
+}
+
+func TestQuote_NoDiscountForShortStay(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 7)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, quote.PriceBreakdown.Discounts)
+	assert.Equal(t, rsd(12000), quote.PriceBreakdown.Subtotal)
+	assert.Equal(t, rsd(12000), quote.TotalPrice)
+}
+
+func TestQuote_WeeklyDiscount(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 8)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PriceBreakdownDTO{
+		BasePrice:        rsd(1000),
+		Nights:           7,
+		NightsTotal:      rsd(7000),
+		ExtraGuestFee:    rsd(0),
+		ExtraGuestsTotal: rsd(0),
+		Subtotal:         rsd(14000),
+		Discounts:        []model.AppliedDiscountDTO{{DiscountRuleId: 1, Type: model.LENGTH_OF_STAY, MinimumNights: 7, Percentage: 10, Amount: rsd(1400)}},
+		Fees:             []model.AppliedFeeDTO{},
+		FeesTotal:        rsd(0),
+		Taxes:            []model.AppliedFeeDTO{},
+		TaxesTotal:       rsd(0),
+		Total:            rsd(12600),
+	}, quote.PriceBreakdown)
+	assert.Equal(t, rsd(12600), quote.TotalPrice)
+}
+
+func TestQuote_OnlyLargestDiscountApplies(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 29)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, quote.PriceBreakdown.Discounts, 1)
+	assert.Equal(t, uint(2), quote.PriceBreakdown.Discounts[0].DiscountRuleId)
+	assert.Equal(t, rsd(7000), quote.PriceBreakdown.Discounts[0].Amount)
+	assert.Equal(t, rsd(21000), quote.TotalPrice)
+}
+
+func TestCreateDiscountRule_InvalidPercentage(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	discountRule, err := accommodationService.CreateDiscountRule(model.DiscountRule{AccomodationID: 1, MinimumNights: 7, Percentage: 120}, 1, context.Background())
+
+	assert.Empty(t, discountRule)
+	assert.EqualError(t, err, "discount percentage has to be between 0 and 100")
+}
+
+func TestCreateDiscountRule_Successfull(t *testing.T) {
+	mockRepo := discountRepo()
+	mockRepo.SaveDiscountRuleFn = func(discountRule model.DiscountRule, ctx context.Context) model.DiscountRule {
+		discountRule.ID = 3
+		return discountRule
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	discountRule, err := accommodationService.CreateDiscountRule(model.DiscountRule{AccomodationID: 1, MinimumNights: 14, Percentage: 15}, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), discountRule.ID)
+}
+
+func leadTimeRepo() *MockRepo {
+	mockRepo := discountRepo()
+	mockRepo.FindDiscountRulesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+		return []model.DiscountRule{
+			{Model: gorm.Model{ID: 1}, AccomodationID: 1, Type: model.LENGTH_OF_STAY, MinimumNights: 7, Percentage: 10},
+			{Model: gorm.Model{ID: 2}, AccomodationID: 1, Type: model.LAST_MINUTE, DaysBeforeCheckIn: 3, Percentage: 20},
+			{Model: gorm.Model{ID: 3}, AccomodationID: 1, Type: model.EARLY_BIRD, DaysBeforeCheckIn: 60, Percentage: 5},
+		}
+	}
+	return mockRepo
+}
+
+func TestQuote_LastMinuteDiscount(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: leadTimeRepo(),
+		Clock: func() time.Time {
+			return time.Date(2023, 5, 29, 9, 0, 0, 0, time.UTC)
+		},
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 3)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AppliedDiscountDTO{{DiscountRuleId: 2, Type: model.LAST_MINUTE, DaysBeforeCheckIn: 3, Percentage: 20, Amount: rsd(400)}}, quote.PriceBreakdown.Discounts)
+	assert.Equal(t, rsd(1600), quote.TotalPrice)
+}
+
+func TestQuote_EarlyBirdAddsUpWithLengthOfStay(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: leadTimeRepo(),
+		Clock: func() time.Time {
+			return time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC)
+		},
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 11)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, quote.PriceBreakdown.Discounts, 2)
+	assert.Equal(t, model.LENGTH_OF_STAY, quote.PriceBreakdown.Discounts[0].Type)
+	assert.Equal(t, model.EARLY_BIRD, quote.PriceBreakdown.Discounts[1].Type)
+	assert.Equal(t, rsd(10000), quote.PriceBreakdown.Subtotal)
+	assert.Equal(t, rsd(8500), quote.TotalPrice)
+}
+
+func TestQuote_NoLeadTimeDiscountInBetween(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: leadTimeRepo(),
+		Clock: func() time.Time {
+			return time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+		},
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 3)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, quote.PriceBreakdown.Discounts)
+	assert.Equal(t, rsd(2000), quote.TotalPrice)
+}
+
+func TestQuote_DiscountsNeverExceedSubtotal(t *testing.T) {
+	mockRepo := discountRepo()
+	mockRepo.FindDiscountRulesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+		return []model.DiscountRule{
+			{Model: gorm.Model{ID: 1}, AccomodationID: 1, Type: model.LENGTH_OF_STAY, MinimumNights: 7, Percentage: 70},
+			{Model: gorm.Model{ID: 2}, AccomodationID: 1, Type: model.EARLY_BIRD, DaysBeforeCheckIn: 60, Percentage: 50},
+		}
+	}
+	mockRepo.FindFeesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.Fee {
+		return []model.Fee{{Model: gorm.Model{ID: 1}, AccomodationID: 1, Name: "VAT", Kind: model.TAX, Basis: model.PERCENTAGE, Percentage: 10}}
+	}
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+		Clock: func() time.Time {
+			return time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC)
+		},
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 11)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, quote.PriceBreakdown.Discounts, 2)
+	assert.Equal(t, rsd(7000), quote.PriceBreakdown.Discounts[0].Amount)
+	assert.Equal(t, rsd(3000), quote.PriceBreakdown.Discounts[1].Amount)
+	assert.Equal(t, rsd(0), quote.PriceBreakdown.TaxesTotal)
+	assert.Equal(t, rsd(0), quote.TotalPrice)
+}
+
+func TestCreateDiscountRule_UnknownType(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	discountRule, err := accommodationService.CreateDiscountRule(model.DiscountRule{AccomodationID: 1, Type: "BLACK FRIDAY", Percentage: 20}, 1, context.Background())
+
+	assert.Empty(t, discountRule)
+	assert.EqualError(t, err, "discount type BLACK FRIDAY does not exist")
+}
@@ -0,0 +1,258 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/exchange"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+var euroRates = exchange.Rates{Base: "EUR", Rates: map[string]float64{"RSD": 117.2, "USD": 1.08}}
+
+func TestRates_CrossRate(t *testing.T) {
+	rate, err := euroRates.Rate("RSD", "USD")
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.08/117.2, rate, 1e-12)
+}
+
+func TestRates_SameCurrency(t *testing.T) {
+	rate, err := euroRates.Rate("JPY", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), rate)
+}
+
+func TestRates_UnknownCurrency(t *testing.T) {
+	_, err := euroRates.Rate("RSD", "GBP")
+
+	assert.EqualError(t, err, "there is no exchange rate from RSD to GBP")
+}
+
+func TestMoney_ConvertBetweenExponents(t *testing.T) {
+	assert.Equal(t, model.NewMoney(5119, "EUR"), rsd(6000).Convert(1/117.2, "EUR"))
+	assert.Equal(t, model.NewMoney(16200, "JPY"), model.NewMoney(10000, "EUR").Convert(162, "JPY"))
+}
+
+func TestLoadStaticProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"base":"EUR","rates":{"RSD":117.2}}`), 0600))
+
+	provider, err := exchange.LoadStaticProvider(path)
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 117.2, rate)
+}
+
+func TestLoadStaticProvider_InvalidRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"base":"EUR","rates":{"RSD":0}}`), 0600))
+
+	_, err := exchange.LoadStaticProvider(path)
+
+	assert.EqualError(t, err, "exchange rate for RSD has to be positive")
+}
+
+// fakeRatesServer serves the given rates table and counts the requests made
+// to it. Once failing is set it responds with an error instead.
+func fakeRatesServer(t *testing.T, body string, requests *int, failing *bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPProvider_CachesRatesForTTL(t *testing.T) {
+	requests, failing := 0, false
+	server := fakeRatesServer(t, `{"base":"EUR","rates":{"RSD":117.2}}`, &requests, &failing)
+	now := date(2023, 6, 1)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+	provider.Clock = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		rate, err := provider.Rate("EUR", "RSD", context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 117.2, rate)
+	}
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(time.Hour)
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestHTTPProvider_KeepsStaleRatesWhenRefreshFails(t *testing.T) {
+	requests, failing := 0, false
+	server := fakeRatesServer(t, `{"base":"EUR","rates":{"RSD":117.2}}`, &requests, &failing)
+	now := date(2023, 6, 1)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+	provider.Clock = func() time.Time { return now }
+
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+
+	failing = true
+	now = now.Add(2 * time.Hour)
+	rate, err := provider.Rate("EUR", "RSD", context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 117.2, rate)
+	assert.Equal(t, 2, requests)
+}
+
+func TestHTTPProvider_FailsWithoutRates(t *testing.T) {
+	requests, failing := 0, true
+	server := fakeRatesServer(t, "", &requests, &failing)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+
+	assert.EqualError(t, err, "exchange rate API responded with status 503")
+}
+
+func TestHTTPProvider_BacksOffAfterFailedRefresh(t *testing.T) {
+	requests, failing := 0, false
+	server := fakeRatesServer(t, `{"base":"EUR","rates":{"RSD":117.2}}`, &requests, &failing)
+	now := date(2023, 6, 1)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+	provider.Clock = func() time.Time { return now }
+
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+
+	failing = true
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		rate, err := provider.Rate("EUR", "RSD", context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 117.2, rate)
+	}
+	assert.Equal(t, 2, requests)
+
+	failing = false
+	now = now.Add(provider.RetryInterval)
+	_, err = provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+}
+
+func TestHTTPProvider_BacksOffWithoutRates(t *testing.T) {
+	requests, failing := 0, true
+	server := fakeRatesServer(t, "", &requests, &failing)
+	now := date(2023, 6, 1)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+	provider.Clock = func() time.Time { return now }
+
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.Error(t, err)
+	_, err = provider.Rate("EUR", "RSD", context.Background())
+
+	assert.EqualError(t, err, "exchange rate API responded with status 503")
+	assert.Equal(t, 1, requests)
+}
+
+func TestHTTPProvider_ServesStaleRatesDuringRefresh(t *testing.T) {
+	blocked := make(chan struct{})
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			<-blocked
+		}
+		w.Write([]byte(`{"base":"EUR","rates":{"RSD":117.2}}`))
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	var mutex sync.Mutex
+	now := date(2023, 6, 1)
+	provider := exchange.NewHTTPProvider(server.URL, time.Hour)
+	provider.Clock = func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return now
+	}
+	_, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+
+	mutex.Lock()
+	now = now.Add(2 * time.Hour)
+	mutex.Unlock()
+	go provider.Rate("EUR", "RSD", context.Background())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&requests) == 2 }, time.Second, time.Millisecond)
+
+	rate, err := provider.Rate("EUR", "RSD", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 117.2, rate)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestQuote_DisplayCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo:          discountRepo(),
+		ExchangeRates: exchange.NewStaticProvider(euroRates),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4), Currency: "EUR"}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, rsd(6000), quote.TotalPrice)
+	assert.Equal(t, &model.DisplayPriceDTO{
+		Currency:   "EUR",
+		Rate:       1 / 117.2,
+		Price:      model.NewMoney(853, "EUR"),
+		TotalPrice: model.NewMoney(5119, "EUR"),
+	}, quote.DisplayPrice)
+}
+
+func TestQuote_WithoutDisplayCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, quote.DisplayPrice)
+}
+
+func TestQuote_UnsupportedDisplayCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo:          discountRepo(),
+		ExchangeRates: exchange.NewStaticProvider(euroRates),
+	}
+
+	_, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4), Currency: "XYZ"}, context.Background())
+
+	assert.EqualError(t, err, "currency XYZ is not supported")
+}
+
+func TestQuote_DisplayCurrencyWithoutRate(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo:          discountRepo(),
+		ExchangeRates: exchange.NewStaticProvider(euroRates),
+	}
+
+	_, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4), Currency: "GBP"}, context.Background())
+
+	assert.EqualError(t, err, "there is no exchange rate from RSD to GBP")
+}
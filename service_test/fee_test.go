@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/handler"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+	"github.com/windbnb/accomodation-service/tracer"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func TestQuote_FeesAndTaxes(t *testing.T) {
+	mockRepo := discountRepo()
+	mockRepo.FindFeesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.Fee {
+		return []model.Fee{
+			{Model: gorm.Model{ID: 1}, AccomodationID: 1, Name: "Cleaning", Kind: model.FEE, Basis: model.PER_STAY, Amount: rsd(2000)},
+			{Model: gorm.Model{ID: 2}, AccomodationID: 1, Name: "Linen", Kind: model.FEE, Basis: model.PER_NIGHT, Amount: rsd(100)},
+			{Model: gorm.Model{ID: 3}, AccomodationID: 1, Name: "Service", Kind: model.FEE, Basis: model.PERCENTAGE, Percentage: 5},
+			{Model: gorm.Model{ID: 4}, AccomodationID: 1, Name: "Tourist tax", Kind: model.TAX, Basis: model.PER_GUEST_NIGHT, Amount: rsd(150)},
+		}
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	// Seven nights for two guests get the weekly discount, so the service fee
+	// is taken from 12600 rather than 14000.
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 8)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, rsd(14000), quote.PriceBreakdown.Subtotal)
+	assert.Equal(t, []model.AppliedFeeDTO{
+		{FeeId: 1, Name: "Cleaning", Basis: model.PER_STAY, Amount: rsd(2000), Total: rsd(2000)},
+		{FeeId: 2, Name: "Linen", Basis: model.PER_NIGHT, Amount: rsd(100), Total: rsd(700)},
+		{FeeId: 3, Name: "Service", Basis: model.PERCENTAGE, Percentage: 5, Total: rsd(630)},
+	}, quote.PriceBreakdown.Fees)
+	assert.Equal(t, rsd(3330), quote.PriceBreakdown.FeesTotal)
+	assert.Equal(t, []model.AppliedFeeDTO{
+		{FeeId: 4, Name: "Tourist tax", Basis: model.PER_GUEST_NIGHT, Amount: rsd(150), Total: rsd(2100)},
+	}, quote.PriceBreakdown.Taxes)
+	assert.Equal(t, rsd(2100), quote.PriceBreakdown.TaxesTotal)
+	assert.Equal(t, rsd(18030), quote.PriceBreakdown.Total)
+	assert.Equal(t, rsd(18030), quote.TotalPrice)
+}
+
+func TestCreateFee_UnknownBasis(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	fee, err := accommodationService.CreateFee(model.Fee{AccomodationID: 1, Name: "Cleaning", Kind: model.FEE, Basis: "PER WEEK", Amount: rsd(10)}, 1, context.Background())
+
+	assert.Empty(t, fee)
+	assert.EqualError(t, err, "fee basis PER WEEK does not exist")
+}
+
+func TestCreateFee_UserDoesNotHaveAccess(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	fee, err := accommodationService.CreateFee(model.Fee{AccomodationID: 1, Name: "Cleaning", Kind: model.FEE, Basis: model.PER_STAY, Amount: rsd(10)}, 2, context.Background())
+
+	assert.Empty(t, fee)
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestFromCreateFeeDTOToFee_KeepsPercentage(t *testing.T) {
+	fee := util.FromCreateFeeDTOToFee(model.CreateFeeDTO{Name: "Service", Kind: model.FEE, Basis: model.PERCENTAGE, Percentage: 5, AccomodationID: 1})
+
+	assert.Equal(t, float32(5), fee.Percentage)
+	assert.Equal(t, model.PERCENTAGE, fee.Basis)
+}
+
+func TestCreateFee_WrongCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: discountRepo(),
+	}
+
+	fee, err := accommodationService.CreateFee(model.Fee{AccomodationID: 1, Name: "Cleaning", Kind: model.FEE, Basis: model.PER_STAY, Amount: model.NewMoney(1000, "EUR")}, 1, context.Background())
+
+	assert.Empty(t, fee)
+	assert.EqualError(t, err, "fee amount has to be in RSD")
+}
+
+func TestFeeHandlers_RejectMalformedRequests(t *testing.T) {
+	feeHandler := &handler.Handler{Service: &service.AccomodationService{Repo: &MockRepo{}}, Tracer: tracer.Global()}
+
+	recorder := httptest.NewRecorder()
+	feeHandler.CreateFee(recorder, httptest.NewRequest(http.MethodPost, "/api/accomodation/fee", bytes.NewBufferString(`{"basis":`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/accomodation/fee/first", nil), map[string]string{"id": "first"})
+	feeHandler.DeleteFee(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "cannot parse fee id", errorMessage(t, recorder))
+}
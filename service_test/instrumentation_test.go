@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/metrics"
+	"github.com/windbnb/accomodation-service/repository"
+	"github.com/windbnb/accomodation-service/tracer"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+func TestObserveDatabaseQuery(t *testing.T) {
+	metrics.ObserveDatabaseQuery("query", "prices", 3*time.Millisecond, false)
+	metrics.ObserveDatabaseQuery("update", "prices", 40*time.Millisecond, true)
+
+	recorder := httptest.NewRecorder()
+	metrics.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	assert.Contains(t, string(body), `database_query_duration_seconds_bucket{operation="query",status="ok",table="prices",le="0.005"} 1`)
+	assert.Contains(t, string(body), `database_query_duration_seconds_count{operation="update",status="error",table="prices"} 1`)
+}
+
+func TestInstrument_QuerySpans_Integration(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	db := util.ConnectToDatabase()
+	defer db.Close()
+	repository.Instrument(db)
+	repo := &repository.Repository{Db: db}
+	parent := tracer.StartSpanFromContext(context.Background(), "findPricesForAccomodationService")
+
+	repo.FindPricesForAccomodation(1, time.Now(), time.Now().AddDate(0, 0, 7), tracer.ContextWithSpan(context.Background(), parent))
+
+	repositorySpan := endedSpan(t, exporter, "findPricesForAccomodationRepository")
+	querySpan := endedSpan(t, exporter, "query prices")
+	assert.Equal(t, repositorySpan.SpanContext.SpanID(), querySpan.Parent.SpanID())
+	assert.Contains(t, attributeValue(querySpan, "db.statement").AsString(), `FROM "prices"`)
+	assert.Equal(t, "postgresql", attributeValue(querySpan, "db.system").AsString())
+}
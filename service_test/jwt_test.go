@@ -0,0 +1,287 @@
+package service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/auth"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+// fakeJWKSServer serves the public parts of keys in JWKS format and counts the
+// requests made to it. Once failing is set it responds with an error instead.
+type fakeJWKSServer struct {
+	*httptest.Server
+	keys     map[string]interface{}
+	requests int
+	failing  bool
+}
+
+func newFakeJWKSServer(t *testing.T) *fakeJWKSServer {
+	fake := &fakeJWKSServer{keys: map[string]interface{}{}}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.requests++
+		if fake.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		webKeys := []map[string]string{}
+		for keyId, key := range fake.keys {
+			switch publicKey := key.(type) {
+			case *rsa.PublicKey:
+				webKeys = append(webKeys, map[string]string{"kty": "RSA", "kid": keyId, "use": "sig",
+					"n": encodeBigInt(publicKey.N), "e": encodeBigInt(big.NewInt(int64(publicKey.E)))})
+			case *ecdsa.PublicKey:
+				webKeys = append(webKeys, map[string]string{"kty": "EC", "kid": keyId, "crv": "P-256",
+					"x": encodeBigInt(publicKey.X), "y": encodeBigInt(publicKey.Y)})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": webKeys})
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, keyId string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyId
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return "Bearer " + signed
+}
+
+func hostClaims() jwt.MapClaims {
+	return jwt.MapClaims{"id": 7, "role": "HOST", "email": "host@windbnb.com", "iss": "user-service",
+		"exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func newVerifier(jwks *fakeJWKSServer) *auth.JWTVerifier {
+	return &auth.JWTVerifier{Keys: auth.NewKeySet(jwks.URL, time.Hour), Issuer: "user-service"}
+}
+
+func TestJWTVerifier_RSA(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+
+	user, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims()), context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.UserResponseDTO{Id: 7, Email: "host@windbnb.com", Role: model.HOST}, user)
+}
+
+func TestJWTVerifier_EC(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	jwks.keys["ec-1"] = &key.PublicKey
+	claims := jwt.MapClaims{"sub": "9", "role": "GUEST", "iss": "user-service", "exp": time.Now().Add(time.Hour).Unix()}
+
+	user, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodES256, "ec-1", key, claims), context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), user.Id)
+	assert.Equal(t, model.GUEST, user.Role)
+}
+
+func TestJWTVerifier_CachesKeys(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+	verifier := newVerifier(jwks)
+	token := signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims())
+
+	for i := 0; i < 3; i++ {
+		_, err := verifier.Authenticate(token, context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, jwks.requests)
+}
+
+func TestJWTVerifier_PicksUpRotatedKey(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+	jwks.keys["rsa-1"] = &oldKey.PublicKey
+	now := time.Now()
+	verifier := newVerifier(jwks)
+	verifier.Keys.Clock = func() time.Time { return now }
+
+	_, err := verifier.Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", oldKey, hostClaims()), context.Background())
+	assert.NoError(t, err)
+
+	jwks.keys["rsa-2"] = &newKey.PublicKey
+	rotatedToken := signToken(t, jwt.SigningMethodRS256, "rsa-2", newKey, hostClaims())
+
+	_, err = verifier.Authenticate(rotatedToken, context.Background())
+	assert.EqualError(t, err, "invalid token: token is signed with unknown key rsa-2")
+
+	now = now.Add(time.Minute)
+	_, err = verifier.Authenticate(rotatedToken, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, jwks.requests)
+}
+
+func TestJWTVerifier_RefreshesAfterInterval(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+	now := time.Now()
+	verifier := newVerifier(jwks)
+	verifier.Keys.Clock = func() time.Time { return now }
+	token := signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims())
+
+	_, err := verifier.Authenticate(token, context.Background())
+	assert.NoError(t, err)
+
+	jwks.failing = true
+	now = now.Add(2 * time.Hour)
+	_, err = verifier.Authenticate(token, context.Background())
+
+	assert.NoError(t, err, "cached keys are used while the JWKS endpoint is down")
+	assert.Equal(t, 2, jwks.requests)
+}
+
+func TestJWTVerifier_BacksOffAfterFailedRefresh(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+	now := time.Now()
+	verifier := newVerifier(jwks)
+	verifier.Keys.Clock = func() time.Time { return now }
+	token := signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims())
+
+	_, err := verifier.Authenticate(token, context.Background())
+	assert.NoError(t, err)
+
+	jwks.failing = true
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err = verifier.Authenticate(token, context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, jwks.requests)
+
+	jwks.failing = false
+	now = now.Add(verifier.Keys.MinRefreshInterval)
+	_, err = verifier.Authenticate(token, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, jwks.requests)
+}
+
+func TestJWTVerifier_BacksOffWithoutKeys(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.failing = true
+	verifier := newVerifier(jwks)
+	token := signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims())
+
+	_, err := verifier.Authenticate(token, context.Background())
+	assert.True(t, errors.Is(err, auth.ErrKeysUnavailable))
+	_, err = verifier.Authenticate(token, context.Background())
+
+	assert.True(t, errors.Is(err, auth.ErrKeysUnavailable))
+	assert.Equal(t, 1, jwks.requests)
+}
+
+func TestJWTVerifier_RejectsForgedSignature(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key, forger := newRSAKey(t), newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+
+	_, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", forger, hostClaims()), context.Background())
+
+	assert.ErrorContains(t, err, "invalid token")
+}
+
+func TestJWTVerifier_RejectsExpiredToken(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+	claims := hostClaims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	_, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", key, claims), context.Background())
+
+	assert.ErrorContains(t, err, "token is expired")
+}
+
+func TestJWTVerifier_RejectsWrongIssuer(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+	claims := hostClaims()
+	claims["iss"] = "someone-else"
+
+	_, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", key, claims), context.Background())
+
+	assert.EqualError(t, err, "invalid token: unexpected issuer")
+}
+
+func TestJWTVerifier_RejectsHMACToken(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	key := newRSAKey(t)
+	jwks.keys["rsa-1"] = &key.PublicKey
+
+	_, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), hostClaims()), context.Background())
+
+	assert.ErrorContains(t, err, "invalid token")
+}
+
+func TestJWTVerifier_KeysUnavailable(t *testing.T) {
+	jwks := newFakeJWKSServer(t)
+	jwks.failing = true
+	key := newRSAKey(t)
+
+	_, err := newVerifier(jwks).Authenticate(signToken(t, jwt.SigningMethodRS256, "rsa-1", key, hostClaims()), context.Background())
+
+	assert.ErrorIs(t, err, auth.ErrKeysUnavailable)
+}
+
+func TestWithFallback(t *testing.T) {
+	remoteCalls := 0
+	remote := func(token string, ctx context.Context) (model.UserResponseDTO, error) {
+		remoteCalls++
+		return model.UserResponseDTO{Id: 3, Role: model.HOST}, nil
+	}
+
+	unavailable := auth.WithFallback(func(token string, ctx context.Context) (model.UserResponseDTO, error) {
+		return model.UserResponseDTO{}, auth.ErrKeysUnavailable
+	}, remote)
+	user, err := unavailable("Bearer token", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), user.Id)
+	assert.Equal(t, 1, remoteCalls)
+
+	rejected := auth.WithFallback(func(token string, ctx context.Context) (model.UserResponseDTO, error) {
+		return model.UserResponseDTO{}, errors.New("invalid token: signature is invalid")
+	}, remote)
+	_, err = rejected("Bearer token", context.Background())
+	assert.EqualError(t, err, "invalid token: signature is invalid")
+	assert.Equal(t, 1, remoteCalls)
+}
@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/metrics"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	metrics.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricProxy_PerRouteMetrics(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/metrics-test/{id}", metrics.MetricProxy(func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(strings.Repeat("a", 150)))
+	})).Methods("GET")
+
+	for _, id := range []string{"1", "2", "missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/"+id, nil))
+	}
+	scraped := scrapeMetrics(t)
+
+	assert.Contains(t, scraped, `http_requests_total{method="GET",route="/metrics-test/{id}",status="200"} 2`)
+	assert.Contains(t, scraped, `http_requests_total{method="GET",route="/metrics-test/{id}",status="404"} 1`)
+	assert.Contains(t, scraped, `http_request_duration_seconds_count{method="GET",route="/metrics-test/{id}",status="200"} 2`)
+	assert.Contains(t, scraped, `http_response_size_bytes_sum{method="GET",route="/metrics-test/{id}",status="200"} 300`)
+	assert.Contains(t, scraped, `http_response_size_bytes_bucket{method="GET",route="/metrics-test/{id}",status="200",le="100"} 0`)
+	assert.Contains(t, scraped, "http_requests_in_flight 0")
+}
+
+func TestMetricProxy_InFlight(t *testing.T) {
+	var inFlight string
+	handler := metrics.MetricProxy(func(w http.ResponseWriter, r *http.Request) {
+		for _, line := range strings.Split(scrapeMetrics(t), "\n") {
+			if strings.HasPrefix(line, "http_requests_in_flight ") {
+				inFlight = line
+			}
+		}
+	})
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/not-routed", nil))
+
+	assert.Equal(t, "http_requests_in_flight 1", inFlight)
+	assert.Contains(t, scrapeMetrics(t), `http_requests_total{method="GET",route="unmatched",status="200"} 1`)
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/handler"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+	"github.com/windbnb/accomodation-service/tracer"
+	"github.com/windbnb/accomodation-service/util"
+)
+
+// rsd returns an amount given in whole dinars.
+func rsd(amount int64) model.Money {
+	return model.NewMoney(amount*100, model.DefaultCurrency)
+}
+
+func TestMoney_PercentRoundsHalfAwayFromZero(t *testing.T) {
+	assert.Equal(t, model.NewMoney(101, "EUR"), model.NewMoney(1005, "EUR").Percent(10))
+	assert.Equal(t, model.NewMoney(100, "EUR"), model.NewMoney(1004, "EUR").Percent(10))
+	assert.Equal(t, model.NewMoney(126, "EUR"), model.NewMoney(1005, "EUR").Percent(12.5))
+	assert.Equal(t, model.NewMoney(-101, "EUR"), model.NewMoney(-1005, "EUR").Percent(10))
+}
+
+func TestMoney_PercentKeepsTwoDecimalsOfThePercentage(t *testing.T) {
+	assert.Equal(t, model.NewMoney(1235, "RSD"), model.NewMoney(10000, "RSD").Percent(12.345))
+}
+
+func TestMoney_AddTakesCurrencyOfNonEmptyAmount(t *testing.T) {
+	assert.Equal(t, model.NewMoney(250, "EUR"), model.Money{}.Add(model.NewMoney(250, "EUR")))
+	assert.Equal(t, model.NewMoney(150, "EUR"), model.NewMoney(250, "EUR").Sub(model.NewMoney(100, "")))
+}
+
+func TestParseMoney(t *testing.T) {
+	money, err := model.ParseMoney("30.5", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(3050, "EUR"), money)
+
+	money, err = model.ParseMoney("-0.05", "RSD")
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(-5, "RSD"), money)
+
+	money, err = model.ParseMoney("1500", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(1500, "JPY"), money)
+}
+
+func TestParseMoney_TooManyDecimals(t *testing.T) {
+	_, err := model.ParseMoney("30.505", "EUR")
+	assert.EqualError(t, err, "amount 30.505 has more decimals than EUR allows")
+
+	_, err = model.ParseMoney("10.5", "JPY")
+	assert.EqualError(t, err, "amount 10.5 has more decimals than JPY allows")
+}
+
+func TestParseMoney_UnsupportedCurrency(t *testing.T) {
+	_, err := model.ParseMoney("10", "XYZ")
+	assert.EqualError(t, err, "currency XYZ is not supported")
+}
+
+func TestMoneyFromFloat_RoundsToMinorUnits(t *testing.T) {
+	assert.Equal(t, model.NewMoney(3000, "RSD"), model.MoneyFromFloat(29.995, "RSD"))
+	assert.Equal(t, model.NewMoney(-1235, "EUR"), model.MoneyFromFloat(-12.345, "EUR"))
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	assert.Equal(t, "30.50", model.NewMoney(3050, "EUR").Decimal())
+	assert.Equal(t, "0.05", model.NewMoney(5, "EUR").Decimal())
+	assert.Equal(t, "-0.05", model.NewMoney(-5, "EUR").Decimal())
+	assert.Equal(t, "1500", model.NewMoney(1500, "JPY").Decimal())
+}
+
+func TestMoney_JSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(model.NewMoney(3050, "EUR"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"30.50","currency":"EUR"}`, string(data))
+
+	var money model.Money
+	assert.NoError(t, json.Unmarshal(data, &money))
+	assert.Equal(t, model.NewMoney(3050, "EUR"), money)
+}
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	var money model.Money
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":12.5,"currency":"USD"}`), &money))
+	assert.Equal(t, model.NewMoney(1250, "USD"), money)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"40"}`), &money))
+	assert.Equal(t, model.NewMoney(4000, model.DefaultCurrency), money)
+
+	assert.NoError(t, json.Unmarshal([]byte(`3000`), &money))
+	assert.Equal(t, model.NewMoney(300000, model.DefaultCurrency), money)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"1.001","currency":"EUR"}`), &money))
+}
+
+func TestUpdatePrice_CurrencyMismatch(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{
+			FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+				return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+			},
+			FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+				return model.Price{Model: gorm.Model{ID: 1}, Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}, nil
+			},
+			SavePriceVersionFn: func(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+				t.Fatal("price in a different currency should not be saved")
+				return price, nil
+			},
+		},
+	}
+
+	price, err := accommodationService.UpdatePrice(model.Price{Value: model.NewMoney(2500, "EUR")}, 1, 1, context.Background())
+
+	assert.EqualError(t, err, "price has to be in RSD")
+	assert.Equal(t, model.Price{}, price)
+}
+
+func TestUpdatePrice_NegativePrice(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{
+			FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+				return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+			},
+			FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+				return model.Price{Model: gorm.Model{ID: 1}, Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}, nil
+			},
+			SavePriceVersionFn: func(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+				t.Fatal("negative price should not be saved")
+				return price, nil
+			},
+		},
+	}
+
+	price, err := accommodationService.UpdatePrice(model.Price{Value: rsd(-100)}, 1, 1, context.Background())
+
+	assert.EqualError(t, err, "price can not be negative")
+	assert.Equal(t, model.Price{}, price)
+}
+
+func TestValidatePriceCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{}
+	accommodation := model.Accomodation{Currency: "EUR"}
+
+	assert.NoError(t, accommodationService.ValidatePriceCurrency(model.Price{Value: model.NewMoney(2500, "EUR")}, accommodation))
+	assert.EqualError(t, accommodationService.ValidatePriceCurrency(model.Price{Value: rsd(3000)}, accommodation), "price has to be in EUR")
+	assert.EqualError(t, accommodationService.ValidatePriceCurrency(model.Price{Value: model.NewMoney(-1, "EUR")}, accommodation), "price can not be negative")
+}
+
+func TestValidatePricing_UnsupportedCurrency(t *testing.T) {
+	accommodationService := service.AccomodationService{}
+
+	err := accommodationService.ValidatePricing(model.Accomodation{PriceType: model.PER_GUEST, Currency: "XYZ"})
+
+	assert.EqualError(t, err, "currency XYZ is not supported")
+}
+
+func TestParseMultipartAccomodation_InvalidExtraGuestFee(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range map[string]string{"name": "Lanterna", "address": "Ljubice Ravasi 32, Novi Sad", "hasWifi": "true",
+		"hasKitchen": "false", "hasAirConditioning": "false", "hasFreeParking": "true", "minimumGuests": "1", "maximumGuests": "4",
+		"priceType": "PER_OCCUPANCY", "currency": "EUR", "extraGuestFee": "12.345"} {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+	request := httptest.NewRequest("POST", "/api/accomodation", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	assert.NoError(t, request.ParseMultipartForm(1<<20))
+
+	_, err := util.ParseMultipartAccomodation(request)
+
+	assert.EqualError(t, err, "amount 12.345 has more decimals than EUR allows")
+}
+
+func TestMigrateDatabase_ConvertsFloatAmounts_Integration(t *testing.T) {
+	db := util.ConnectToDatabase()
+	defer db.Close()
+	db.Exec("ALTER TABLE prices ADD COLUMN value double precision")
+	db.Exec("ALTER TABLE fees ADD COLUMN amount double precision")
+	var priceId, chargeId, percentageId uint
+	db.Raw("INSERT INTO prices (start_date, end_date, value, price_duration, accomodation_id, active) VALUES (?, ?, ?, ?, ?, ?) RETURNING id",
+		date(2023, 1, 1), date(2024, 1, 1), 3000.5, model.REGULAR, 1, true).Row().Scan(&priceId)
+	db.Raw("INSERT INTO fees (name, kind, basis, amount, accomodation_id) VALUES (?, ?, ?, ?, ?) RETURNING id",
+		"Cleaning", model.FEE, model.PER_STAY, 20.25, 1).Row().Scan(&chargeId)
+	db.Raw("INSERT INTO fees (name, kind, basis, amount, accomodation_id) VALUES (?, ?, ?, ?, ?) RETURNING id",
+		"Service", model.FEE, model.PERCENTAGE, 5, 1).Row().Scan(&percentageId)
+
+	assert.NoError(t, util.MigrateDatabase(db))
+
+	var price model.Price
+	var charge, percentage model.Fee
+	assert.NoError(t, db.First(&price, priceId).Error)
+	assert.NoError(t, db.First(&charge, chargeId).Error)
+	assert.NoError(t, db.First(&percentage, percentageId).Error)
+	assert.Equal(t, model.NewMoney(300050, "RSD"), price.Value)
+	assert.Equal(t, model.NewMoney(2025, "RSD"), charge.Amount)
+	assert.Equal(t, float32(5), percentage.Percentage)
+	assert.Equal(t, model.NewMoney(0, "RSD"), percentage.Amount)
+	assert.False(t, db.Dialect().HasColumn("prices", "value"))
+	assert.False(t, db.Dialect().HasColumn("fees", "amount"))
+}
+
+func TestMigrateDatabase_KeepsOldColumnWhenConversionFails_Integration(t *testing.T) {
+	db := util.ConnectToDatabase()
+	defer db.Close()
+	db.Exec("ALTER TABLE prices ADD COLUMN value text")
+	defer db.Exec("ALTER TABLE prices DROP COLUMN IF EXISTS value")
+	var priceId uint
+	db.Raw("INSERT INTO prices (start_date, end_date, value, price_duration, accomodation_id, active) VALUES (?, ?, ?, ?, ?, ?) RETURNING id",
+		date(2023, 1, 1), date(2024, 1, 1), "thirty", model.REGULAR, 1, true).Row().Scan(&priceId)
+	defer db.Exec("DELETE FROM prices WHERE id = ?", priceId)
+
+	err := util.MigrateDatabase(db)
+
+	assert.ErrorContains(t, err, "converting prices.value")
+	assert.True(t, db.Dialect().HasColumn("prices", "value"))
+}
+
+func TestCreatePrice_RejectsInvalidAmount(t *testing.T) {
+	priceHandler := &handler.Handler{
+		Service: &service.AccomodationService{
+			Repo: &MockRepo{
+				SavePriceFn: func(price model.Price, ctx context.Context) model.Price {
+					t.Fatal("no price should be saved from a request with an invalid amount")
+					return price
+				},
+			},
+		},
+		Tracer: tracer.Global(),
+	}
+	body := `[{"value":{"amount":"3000","currency":"RSD"},"accomodationId":1},{"value":{"amount":"12.345","currency":"RSD"},"accomodationId":1}]`
+
+	recorder := httptest.NewRecorder()
+	priceHandler.CreatePrice(recorder, httptest.NewRequest(http.MethodPost, "/api/accomodation/price", bytes.NewBufferString(body)))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "amount 12.345 has more decimals than RSD allows", errorMessage(t, recorder))
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func occupancyRepo() *MockRepo {
+	mockRepo := discountRepo()
+	mockRepo.FindAccomodationByIdFn = func(id uint, ctx context.Context) (model.Accomodation, error) {
+		return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1, MinimimGuests: 1, MaximumGuests: 5,
+			PriceType: model.PER_OCCUPANCY, BaseOccupancy: 2, ExtraGuestFee: rsd(250)}, nil
+	}
+	mockRepo.FindDiscountRulesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+		return []model.DiscountRule{}
+	}
+	mockRepo.FindFeesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.Fee {
+		return []model.Fee{}
+	}
+	return mockRepo
+}
+
+func TestQuote_PerOccupancyWithinBaseOccupancy(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: occupancyRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), quote.PriceBreakdown.ExtraGuests)
+	assert.Equal(t, rsd(0), quote.PriceBreakdown.ExtraGuestsTotal)
+	assert.Equal(t, rsd(3000), quote.TotalPrice)
+}
+
+func TestQuote_PerOccupancyWithExtraGuests(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: occupancyRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 5, StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 4)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PriceBreakdownDTO{
+		BasePrice:        rsd(1000),
+		Nights:           3,
+		NightsTotal:      rsd(3000),
+		ExtraGuests:      3,
+		ExtraGuestFee:    rsd(250),
+		ExtraGuestsTotal: rsd(2250),
+		Subtotal:         rsd(5250),
+		Discounts:        []model.AppliedDiscountDTO{},
+		Fees:             []model.AppliedFeeDTO{},
+		FeesTotal:        rsd(0),
+		Taxes:            []model.AppliedFeeDTO{},
+		TaxesTotal:       rsd(0),
+		Total:            rsd(5250),
+	}, quote.PriceBreakdown)
+}
+
+func TestUpdateOccupancyPricing_BaseOccupancyAboveMaximum(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: occupancyRepo(),
+	}
+
+	accommodation, err := accommodationService.UpdateOccupancyPricing(1, model.OccupancyPricingDTO{PriceType: model.PER_OCCUPANCY, BaseOccupancy: 6, ExtraGuestFee: rsd(10)}, 1, context.Background())
+
+	assert.Empty(t, accommodation)
+	assert.EqualError(t, err, "base occupancy can not be higher than the maximum number of guests")
+}
+
+func TestUpdateOccupancyPricing_Successfull(t *testing.T) {
+	mockRepo := occupancyRepo()
+	mockRepo.UpdateAccommodationFn = func(accomodation model.Accomodation, ctx context.Context) model.Accomodation {
+		return accomodation
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	accommodation, err := accommodationService.UpdateOccupancyPricing(1, model.OccupancyPricingDTO{PriceType: model.PER_OCCUPANCY, BaseOccupancy: 3, ExtraGuestFee: rsd(400)}, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), accommodation.BaseOccupancy)
+	assert.Equal(t, rsd(400), accommodation.ExtraGuestFee)
+}
+
+func TestValidatePricing_UnknownPriceType(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{},
+	}
+
+	err := accommodationService.ValidatePricing(model.Accomodation{PriceType: "PER NIGHT"})
+
+	assert.EqualError(t, err, "price type PER NIGHT does not exist")
+}
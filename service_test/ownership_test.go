@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/handler"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/router"
+	"github.com/windbnb/accomodation-service/service"
+	"github.com/windbnb/accomodation-service/tracer"
+)
+
+// ownedPriceRepo holds a price and an available term of accomodation 1, which
+// belongs to host 1. Any write fails the test.
+func ownedPriceRepo(t *testing.T) *MockRepo {
+	fail := func() { t.Fatal("a host changed an accomodation they do not own") }
+	return &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+		},
+		FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+			return model.Price{Model: gorm.Model{ID: uint(id)}, Value: rsd(3000), AccomodationID: 1, Active: true}, nil
+		},
+		FindAvailableTermByIdFn: func(id uint64, ctx context.Context) (model.AvailableTerm, error) {
+			return model.AvailableTerm{Model: gorm.Model{ID: uint(id)}, StartDate: date(2023, 6, 1), EndDate: date(2023, 7, 1), AccomodationID: 1}, nil
+		},
+		SavePriceFn: func(price model.Price, ctx context.Context) model.Price {
+			fail()
+			return price
+		},
+		UpdatePriceFn: func(price model.Price, ctx context.Context) model.Price {
+			fail()
+			return price
+		},
+		SavePriceVersionFn: func(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+			fail()
+			return price, nil
+		},
+		SaveAvailableTermFn: func(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+			fail()
+			return availableTerm
+		},
+		UpdateAvailableTermFn: func(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+			fail()
+			return availableTerm
+		},
+		DeleteAvailableTermFn: func(id uint64, ctx context.Context) error {
+			fail()
+			return nil
+		},
+	}
+}
+
+func TestSavePrice_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	_, err := accommodationService.SavePrice(model.Price{Value: rsd(3000), AccomodationID: 1}, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestSavePrice_Owner(t *testing.T) {
+	mockRepo := ownedPriceRepo(t)
+	mockRepo.SavePriceFn = func(price model.Price, ctx context.Context) model.Price {
+		price.ID = 1
+		return price
+	}
+	accommodationService := service.AccomodationService{Repo: mockRepo}
+
+	price, err := accommodationService.SavePrice(model.Price{Value: rsd(3000), AccomodationID: 1}, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), price.ID)
+	assert.True(t, price.Active)
+}
+
+func TestUpdatePrice_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	_, err := accommodationService.UpdatePrice(model.Price{Value: rsd(3500)}, 1, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestDeletePrice_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	err := accommodationService.DeletePrice(1, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestSaveAvailableTerm_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	_, err := accommodationService.SaveAvailableTerm(model.AvailableTerm{StartDate: date(2023, 6, 1), EndDate: date(2023, 7, 1), AccomodationID: 1}, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestUpdateAvailableTerm_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	_, err := accommodationService.UpdateAvailableTerm(model.AvailableTerm{StartDate: date(2023, 6, 1), EndDate: date(2023, 8, 1)}, 1, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestDeleteAvailableTerm_NotOwner(t *testing.T) {
+	accommodationService := service.AccomodationService{Repo: ownedPriceRepo(t)}
+
+	err := accommodationService.DeleteAvailableTerm(1, 2, context.Background())
+
+	assert.EqualError(t, err, "You don't have access to this entity.")
+}
+
+func TestAuthorizeServiceCaller(t *testing.T) {
+	accommodationService := service.AccomodationService{ServiceToken: "reservation-secret"}
+
+	assert.NoError(t, accommodationService.AuthorizeServiceCaller("reservation-secret"))
+	assert.EqualError(t, accommodationService.AuthorizeServiceCaller("guess"), "caller is not an authorized service")
+	assert.EqualError(t, accommodationService.AuthorizeServiceCaller(""), "caller is not an authorized service")
+}
+
+func TestAuthorizeServiceCaller_NotConfigured(t *testing.T) {
+	accommodationService := service.AccomodationService{}
+
+	assert.EqualError(t, accommodationService.AuthorizeServiceCaller(""), "service callers are not configured")
+}
+
+func TestDeleteHostAccomodation_OnlyForServices(t *testing.T) {
+	deletedHostId := uint(0)
+	accommodationService := &service.AccomodationService{
+		Repo: &MockRepo{
+			DeleteHostAccomodationFn: func(hostId uint, ctx context.Context) error {
+				deletedHostId = hostId
+				return nil
+			},
+		},
+		ServiceToken: "user-service-secret",
+	}
+	routes := router.ConfigureRouter(&handler.Handler{Service: accommodationService, Tracer: tracer.Global()})
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/accomodation/delete-all/1", nil)
+	recorder := httptest.NewRecorder()
+	routes.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, uint(0), deletedHostId)
+
+	request.Header.Set(service.ServiceTokenHeader, "user-service-secret")
+	recorder = httptest.NewRecorder()
+	routes.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, uint(1), deletedHostId)
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func preparedAccomodation() model.Accomodation {
+	return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1, MinimimGuests: 1, MaximumGuests: 4, PriceType: model.PER_ACCOMODATION_UNIT,
+		CheckInTime: "15:00", CheckOutTime: "11:00", PreparationDays: 1, TimeZone: "UTC"}
+}
+
+// preparedRepo holds one reservation from 2023-06-05 to 2023-06-08 and lets
+// IsReserved compare against it the way the database would.
+func preparedRepo() *MockRepo {
+	reservedTerm := model.ReservedTerm{StartDate: time.Date(2023, 6, 5, 15, 0, 0, 0, time.UTC), EndDate: time.Date(2023, 6, 8, 11, 0, 0, 0, time.UTC), AccomodationID: 1}
+
+	return &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return preparedAccomodation(), nil
+		},
+		FindAccomodationByGuestsAndAddressFn: func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+			return []model.Accomodation{preparedAccomodation()}
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return true
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return reservedTerm.StartDate.Before(endDate) && reservedTerm.EndDate.After(startDate)
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+		FindAvailableTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+			return []model.AvailableTerm{{StartDate: date(2023, 6, 1), EndDate: date(2023, 6, 30), AccomodationID: 1}}
+		},
+		FindReservedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+			return []model.ReservedTerm{reservedTerm}
+		},
+		FindBlockedTermsBetweenFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+			return []model.BlockedTerm{}
+		},
+		FindAvailabilityRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+			return []model.AvailabilityRule{}
+		},
+	}
+}
+
+func TestSearchAccomodations_RespectsPreparationDays(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: preparedRepo(),
+	}
+
+	search := func(startDate time.Time, endDate time.Time) []model.SearchAccomodationReturnDTO {
+		return accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: startDate, EndDate: endDate}, context.Background())
+	}
+
+	assert.Len(t, search(date(2023, 6, 1), date(2023, 6, 4)), 1)
+	assert.Empty(t, search(date(2023, 6, 1), date(2023, 6, 5)))
+	assert.Empty(t, search(date(2023, 6, 8), date(2023, 6, 10)))
+	assert.Len(t, search(date(2023, 6, 9), date(2023, 6, 11)), 1)
+}
+
+func TestGetCalendar_BlocksPreparationDays(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: preparedRepo(),
+	}
+
+	calendar, err := accommodationService.GetCalendar(1, date(2023, 6, 3), date(2023, 6, 9), context.Background())
+
+	statuses := []model.CalendarDayStatus{}
+	for _, calendarDay := range calendar.Days {
+		statuses = append(statuses, calendarDay.Status)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CalendarDayStatus{model.AVAILABLE, model.BLOCKED, model.RESERVED, model.RESERVED, model.RESERVED, model.BLOCKED, model.AVAILABLE}, statuses)
+}
+
+func TestUpdatePreparationDays_TooManyDays(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: preparedRepo(),
+	}
+
+	accommodation, err := accommodationService.UpdatePreparationDays(1, 31, 1, context.Background())
+
+	assert.Empty(t, accommodation)
+	assert.EqualError(t, err, "preparation days can not exceed 30")
+}
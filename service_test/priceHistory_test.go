@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func TestUpdatePrice_CreatesNewVersion(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	var deactivated model.Price
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{
+			FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+				return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+			},
+			FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+				return model.Price{Model: gorm.Model{ID: 1}, StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(3000),
+					PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}, nil
+			},
+			SavePriceVersionFn: func(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+				deactivated = previousPrice
+				price.ID = 2
+				return price, nil
+			},
+		},
+		Clock: func() time.Time { return now },
+	}
+
+	price, err := accommodationService.UpdatePrice(model.Price{StartDate: date(2023, 6, 1), EndDate: date(2024, 1, 1), Value: rsd(3500)}, 1, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), price.ID)
+	assert.Equal(t, rsd(3500), price.Value)
+	assert.Equal(t, model.REGULAR, price.PriceDuration)
+	assert.Equal(t, uint(1), price.AccomodationID)
+	assert.Equal(t, uint(1), price.PreviousPriceID)
+	assert.True(t, price.Active)
+	assert.Equal(t, now, price.CreatedAt)
+
+	assert.Equal(t, uint(1), deactivated.ID)
+	assert.False(t, deactivated.Active)
+	assert.Equal(t, &now, deactivated.DeactivatedAt)
+	assert.Equal(t, rsd(3000), deactivated.Value)
+}
+
+func TestUpdatePrice_InactiveVersion(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{
+			FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+				return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+			},
+			FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+				return model.Price{Model: gorm.Model{ID: 1}, Value: rsd(3000), AccomodationID: 1, Active: false}, nil
+			},
+		},
+	}
+
+	_, err := accommodationService.UpdatePrice(model.Price{Value: rsd(3500)}, 1, 1, context.Background())
+
+	assert.EqualError(t, err, "price with given id is no longer active")
+}
+
+func TestDeletePrice_Deactivates(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	var updated model.Price
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{
+			FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+				return model.Accomodation{Model: gorm.Model{ID: id}, UserId: 1}, nil
+			},
+			FindPriceByIdFn: func(id uint64, ctx context.Context) (model.Price, error) {
+				return model.Price{Model: gorm.Model{ID: 1}, Value: rsd(3000), AccomodationID: 1, Active: true}, nil
+			},
+			UpdatePriceFn: func(price model.Price, ctx context.Context) model.Price {
+				updated = price
+				return price
+			},
+		},
+		Clock: func() time.Time { return now },
+	}
+
+	err := accommodationService.DeletePrice(1, 1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), updated.ID)
+	assert.False(t, updated.Active)
+	assert.Equal(t, &now, updated.DeactivatedAt)
+}
+
+func priceHistoryRepo() *MockRepo {
+	replacedAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	firstVersion := model.Price{Model: gorm.Model{ID: 1, CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}, Value: rsd(3000),
+		AccomodationID: 1, Active: false, DeactivatedAt: &replacedAt}
+	secondVersion := model.Price{Model: gorm.Model{ID: 2, CreatedAt: replacedAt}, Value: rsd(3500),
+		AccomodationID: 1, Active: true, PreviousPriceID: 1}
+
+	return &MockRepo{
+		GetPriceHistoryForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Price {
+			return []model.Price{firstVersion, secondVersion}
+		},
+	}
+}
+
+func TestGetPriceHistoryForAccomodation(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: priceHistoryRepo(),
+	}
+
+	history := accommodationService.GetPriceHistoryForAccomodation(1, nil, context.Background())
+
+	assert.Len(t, history, 2)
+	assert.False(t, history[0].Active)
+	assert.Equal(t, time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC), *history[0].ActiveTo)
+	assert.True(t, history[1].Active)
+	assert.Equal(t, uint(1), history[1].PreviousPriceId)
+	assert.Nil(t, history[1].ActiveTo)
+}
+
+func TestGetPriceHistoryForAccomodation_At(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: priceHistoryRepo(),
+	}
+
+	beforeChange := accommodationService.GetPriceHistoryForAccomodation(1, timePointer(time.Date(2023, 6, 1, 11, 59, 0, 0, time.UTC)), context.Background())
+	atChange := accommodationService.GetPriceHistoryForAccomodation(1, timePointer(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)), context.Background())
+	beforeFirst := accommodationService.GetPriceHistoryForAccomodation(1, timePointer(time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)), context.Background())
+
+	assert.Len(t, beforeChange, 1)
+	assert.Equal(t, rsd(3000), beforeChange[0].Value)
+	assert.Len(t, atChange, 1)
+	assert.Equal(t, rsd(3500), atChange[0].Value)
+	assert.Empty(t, beforeFirst)
+}
+
+func timePointer(moment time.Time) *time.Time {
+	return &moment
+}
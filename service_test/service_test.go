@@ -69,7 +69,7 @@ func TestUpdateAccommodationAcceptReservationType_Successfull(t *testing.T) {
 				UserId: 2,
 				Prices: []model.Price{
 					{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
-						Value: 3000, PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}},
+						Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}},
 				PriceType:             model.PER_GUEST,
 				AcceptReservationType: model.MANUAL,
 			}, nil
@@ -98,7 +98,7 @@ func TestUpdateAccommodationAcceptReservationType_Successfull(t *testing.T) {
 		UserId: 2,
 		Prices: []model.Price{
 			{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
-				Value: 3000, PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}},
+				Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}},
 		PriceType:             model.PER_GUEST,
 		AcceptReservationType: model.AUTOMATICALLY,
 	}
@@ -146,8 +146,44 @@ func TestUpdateAccommodationAcceptReservationType_Successfull_Integration(t *tes
 
 type MockRepo struct {
 	repository.Repository
-	UpdateAccommodationFn  func(accomodation model.Accomodation, ctx context.Context) model.Accomodation
-	FindAccomodationByIdFn func(id uint, ctx context.Context) (model.Accomodation, error)
+	UpdateAccommodationFn                  func(accomodation model.Accomodation, ctx context.Context) model.Accomodation
+	FindAccomodationByIdFn                 func(id uint, ctx context.Context) (model.Accomodation, error)
+	FindAvailableTermsBetweenFn            func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm
+	FindReservedTermsBetweenFn             func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm
+	FindPricesForAccomodationFn            func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price
+	GetReservedTermsForAccomodationFn      func(accomodationId uint, ctx context.Context) []model.ReservedTerm
+	GetAvailableTermsForAccomodationFn     func(accomodationId uint, ctx context.Context) []model.AvailableTerm
+	SaveCalendarImportFn                   func(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport
+	UpdateCalendarImportFn                 func(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport
+	FindCalendarImportsForAccomodationFn   func(accomodationId uint, ctx context.Context) []model.CalendarImport
+	FindUrlCalendarImportsFn               func(ctx context.Context) []model.CalendarImport
+	SaveBlockedTermFn                      func(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm
+	UpdateBlockedTermFn                    func(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm
+	DeleteBlockedTermFn                    func(id uint, ctx context.Context) error
+	FindBlockedTermsForCalendarImportFn    func(calendarImportId uint, ctx context.Context) []model.BlockedTerm
+	FindAccomodationByGuestsAndAddressFn   func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation
+	IsAvailableFn                          func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
+	IsReservedFn                           func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
+	IsBlockedFn                            func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool
+	FindImagesForAccomodationFn            func(accomodationId uint, ctx context.Context) []string
+	FindBlockedTermsBetweenFn              func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm
+	FindAvailabilityRulesForAccomodationFn func(accomodationId uint, ctx context.Context) []model.AvailabilityRule
+	SaveAvailabilityRuleFn                 func(availabilityRule model.AvailabilityRule, ctx context.Context) model.AvailabilityRule
+	FindStayRulesForAccomodationFn         func(accomodationId uint, ctx context.Context) []model.StayRule
+	SaveReservedTermFn                     func(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm
+	FindDiscountRulesForAccomodationFn     func(accomodationId uint, ctx context.Context) []model.DiscountRule
+	SaveDiscountRuleFn                     func(discountRule model.DiscountRule, ctx context.Context) model.DiscountRule
+	FindFeesForAccomodationFn              func(accomodationId uint, ctx context.Context) []model.Fee
+	FindPriceByIdFn                        func(id uint64, ctx context.Context) (model.Price, error)
+	UpdatePriceFn                          func(price model.Price, ctx context.Context) model.Price
+	GetPriceHistoryForAccomodationFn       func(accomodationId uint, ctx context.Context) []model.Price
+	SavePriceVersionFn                     func(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error)
+	SavePriceFn                            func(price model.Price, ctx context.Context) model.Price
+	SaveAvailableTermFn                    func(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm
+	UpdateAvailableTermFn                  func(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm
+	DeleteAvailableTermFn                  func(id uint64, ctx context.Context) error
+	FindAvailableTermByIdFn                func(id uint64, ctx context.Context) (model.AvailableTerm, error)
+	DeleteHostAccomodationFn               func(hostId uint, ctx context.Context) error
 }
 
 func (m *MockRepo) UpdateAccommodation(accomodation model.Accomodation, ctx context.Context) model.Accomodation {
@@ -157,3 +193,147 @@ func (m *MockRepo) UpdateAccommodation(accomodation model.Accomodation, ctx cont
 func (m *MockRepo) FindAccomodationById(id uint, ctx context.Context) (model.Accomodation, error) {
 	return m.FindAccomodationByIdFn(id, ctx)
 }
+
+func (m *MockRepo) FindAvailableTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.AvailableTerm {
+	return m.FindAvailableTermsBetweenFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) FindReservedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.ReservedTerm {
+	return m.FindReservedTermsBetweenFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) FindPricesForAccomodation(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+	return m.FindPricesForAccomodationFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) GetReservedTermsForAccomodation(accomodationId uint, ctx context.Context) []model.ReservedTerm {
+	return m.GetReservedTermsForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) GetAvailableTermsForAccomodation(accomodationId uint, ctx context.Context) []model.AvailableTerm {
+	return m.GetAvailableTermsForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) SaveCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+	return m.SaveCalendarImportFn(calendarImport, ctx)
+}
+
+func (m *MockRepo) UpdateCalendarImport(calendarImport model.CalendarImport, ctx context.Context) model.CalendarImport {
+	return m.UpdateCalendarImportFn(calendarImport, ctx)
+}
+
+func (m *MockRepo) FindCalendarImportsForAccomodation(accomodationId uint, ctx context.Context) []model.CalendarImport {
+	return m.FindCalendarImportsForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) FindUrlCalendarImports(ctx context.Context) []model.CalendarImport {
+	return m.FindUrlCalendarImportsFn(ctx)
+}
+
+func (m *MockRepo) SaveBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+	return m.SaveBlockedTermFn(blockedTerm, ctx)
+}
+
+func (m *MockRepo) UpdateBlockedTerm(blockedTerm model.BlockedTerm, ctx context.Context) model.BlockedTerm {
+	return m.UpdateBlockedTermFn(blockedTerm, ctx)
+}
+
+func (m *MockRepo) DeleteBlockedTerm(id uint, ctx context.Context) error {
+	return m.DeleteBlockedTermFn(id, ctx)
+}
+
+func (m *MockRepo) FindBlockedTermsForCalendarImport(calendarImportId uint, ctx context.Context) []model.BlockedTerm {
+	return m.FindBlockedTermsForCalendarImportFn(calendarImportId, ctx)
+}
+
+func (m *MockRepo) FindAccomodationByGuestsAndAddress(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+	return m.FindAccomodationByGuestsAndAddressFn(numberOfGuests, address, ctx)
+}
+
+func (m *MockRepo) IsAvailable(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+	return m.IsAvailableFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) IsReserved(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+	return m.IsReservedFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) IsBlocked(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+	return m.IsBlockedFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) FindImagesForAccomodation(accomodationId uint, ctx context.Context) []string {
+	return m.FindImagesForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) FindBlockedTermsBetween(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.BlockedTerm {
+	return m.FindBlockedTermsBetweenFn(accomodationId, startDate, endDate, ctx)
+}
+
+func (m *MockRepo) FindAvailabilityRulesForAccomodation(accomodationId uint, ctx context.Context) []model.AvailabilityRule {
+	return m.FindAvailabilityRulesForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) SaveAvailabilityRule(availabilityRule model.AvailabilityRule, ctx context.Context) model.AvailabilityRule {
+	return m.SaveAvailabilityRuleFn(availabilityRule, ctx)
+}
+
+func (m *MockRepo) FindStayRulesForAccomodation(accomodationId uint, ctx context.Context) []model.StayRule {
+	return m.FindStayRulesForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) SaveReservedTerm(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
+	return m.SaveReservedTermFn(reservedTerm, ctx)
+}
+
+func (m *MockRepo) FindDiscountRulesForAccomodation(accomodationId uint, ctx context.Context) []model.DiscountRule {
+	return m.FindDiscountRulesForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) SaveDiscountRule(discountRule model.DiscountRule, ctx context.Context) model.DiscountRule {
+	return m.SaveDiscountRuleFn(discountRule, ctx)
+}
+
+func (m *MockRepo) FindFeesForAccomodation(accomodationId uint, ctx context.Context) []model.Fee {
+	return m.FindFeesForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) FindPriceById(id uint64, ctx context.Context) (model.Price, error) {
+	return m.FindPriceByIdFn(id, ctx)
+}
+
+func (m *MockRepo) UpdatePrice(price model.Price, ctx context.Context) model.Price {
+	return m.UpdatePriceFn(price, ctx)
+}
+
+func (m *MockRepo) GetPriceHistoryForAccomodation(accomodationId uint, ctx context.Context) []model.Price {
+	return m.GetPriceHistoryForAccomodationFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) SavePriceVersion(previousPrice model.Price, price model.Price, ctx context.Context) (model.Price, error) {
+	return m.SavePriceVersionFn(previousPrice, price, ctx)
+}
+
+func (m *MockRepo) SavePrice(price model.Price, ctx context.Context) model.Price {
+	return m.SavePriceFn(price, ctx)
+}
+
+func (m *MockRepo) SaveAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+	return m.SaveAvailableTermFn(availableTerm, ctx)
+}
+
+func (m *MockRepo) UpdateAvailableTerm(availableTerm model.AvailableTerm, ctx context.Context) model.AvailableTerm {
+	return m.UpdateAvailableTermFn(availableTerm, ctx)
+}
+
+func (m *MockRepo) DeleteAvailableTerm(id uint64, ctx context.Context) error {
+	return m.DeleteAvailableTermFn(id, ctx)
+}
+
+func (m *MockRepo) FindAvailableTermById(id uint64, ctx context.Context) (model.AvailableTerm, error) {
+	return m.FindAvailableTermByIdFn(id, ctx)
+}
+
+func (m *MockRepo) DeleteHostAccomodation(hostId uint, ctx context.Context) error {
+	return m.DeleteHostAccomodationFn(hostId, ctx)
+}
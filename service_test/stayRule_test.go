@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func stayRuleRepo() *MockRepo {
+	return &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, UserId: 1, MinimimGuests: 1, MaximumGuests: 4, PriceType: model.PER_GUEST}, nil
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{
+				{AccomodationID: 1, MinimumNights: 2, MaximumNights: 30},
+				{AccomodationID: 1, StartDate: date(2023, 6, 1), EndDate: date(2023, 8, 31), MinimumNights: 3},
+			}
+		},
+		SaveReservedTermFn: func(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
+			reservedTerm.ID = 1
+			return reservedTerm
+		},
+	}
+}
+
+func TestSaveReservedTerm_BelowSeasonalMinimum(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: date(2023, 7, 1), EndDate: date(2023, 7, 3), AccomodationID: 1}, context.Background())
+
+	assert.Empty(t, reservedTerm)
+	assert.EqualError(t, err, "minimum stay for these dates is 3 nights")
+}
+
+func TestSaveReservedTerm_AboveDefaultMaximum(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: date(2023, 10, 1), EndDate: date(2023, 11, 15), AccomodationID: 1}, context.Background())
+
+	assert.Empty(t, reservedTerm)
+	assert.EqualError(t, err, "maximum stay for these dates is 30 nights")
+}
+
+func TestSaveReservedTerm_Successfull(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: date(2023, 10, 1), EndDate: date(2023, 10, 3), AccomodationID: 1}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), reservedTerm.ID)
+}
+
+func TestCreateStayRule_MaximumBelowMinimum(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	stayRule, err := accommodationService.CreateStayRule(model.StayRule{AccomodationID: 1, MinimumNights: 5, MaximumNights: 3}, 1, context.Background())
+
+	assert.Empty(t, stayRule)
+	assert.EqualError(t, err, "maximum number of nights can not be lower than the minimum")
+}
+
+func TestQuote_BelowMinimumStay(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 10), EndDate: date(2023, 6, 12)}, context.Background())
+
+	assert.Empty(t, quote)
+	assert.EqualError(t, err, "minimum stay for these dates is 3 nights")
+}
+
+func TestQuote_TooManyGuests(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: stayRuleRepo(),
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 6, StartDate: date(2023, 6, 10), EndDate: date(2023, 6, 14)}, context.Background())
+
+	assert.Empty(t, quote)
+	assert.EqualError(t, err, "accomodation hosts between 1 and 4 guests")
+}
+
+func TestQuote_Successfull(t *testing.T) {
+	mockRepo := stayRuleRepo()
+	mockRepo.IsAvailableFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+		return true
+	}
+	mockRepo.IsReservedFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+		return false
+	}
+	mockRepo.IsBlockedFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+		return false
+	}
+	mockRepo.FindPricesForAccomodationFn = func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+		return []model.Price{{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}}
+	}
+	mockRepo.FindImagesForAccomodationFn = func(accomodationId uint, ctx context.Context) []string {
+		return []string{"slika1.jpg"}
+	}
+	mockRepo.FindDiscountRulesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+		return []model.DiscountRule{}
+	}
+	mockRepo.FindFeesForAccomodationFn = func(accomodationId uint, ctx context.Context) []model.Fee {
+		return []model.Fee{}
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	quote, err := accommodationService.Quote(1, model.SearchAccomodationDTO{NumberOfGuests: 2, StartDate: date(2023, 6, 10), EndDate: date(2023, 6, 14)}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, rsd(3000), quote.Price)
+	assert.Equal(t, rsd(24000), quote.TotalPrice)
+	assert.Equal(t, []string{"slika1.jpg"}, quote.Accomodation.Images)
+}
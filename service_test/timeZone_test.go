@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/service"
+)
+
+func TestSearchAccomodations_UsesCalendarDatesOfTheGuest(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	belgrade, _ := time.LoadLocation("Europe/Belgrade")
+	var reservedFrom, reservedTo time.Time
+	mockRepo := &MockRepo{
+		FindAccomodationByGuestsAndAddressFn: func(numberOfGuests uint, address string, ctx context.Context) []model.Accomodation {
+			return []model.Accomodation{{Model: gorm.Model{ID: 1}, PriceType: model.PER_GUEST}}
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		IsAvailableFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return true
+		},
+		IsReservedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			reservedFrom, reservedTo = startDate, endDate
+			return false
+		},
+		IsBlockedFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) bool {
+			return false
+		},
+		FindPricesForAccomodationFn: func(accomodationId uint, startDate time.Time, endDate time.Time, ctx context.Context) []model.Price {
+			return []model.Price{{StartDate: date(2023, 1, 1), EndDate: date(2024, 1, 1), Value: rsd(3000), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true}}
+		},
+		FindImagesForAccomodationFn: func(accomodationId uint, ctx context.Context) []string {
+			return []string{}
+		},
+		FindDiscountRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.DiscountRule {
+			return []model.DiscountRule{}
+		},
+		FindFeesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.Fee {
+			return []model.Fee{}
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	// Midnight in Tokyo is still the previous day in UTC and in Belgrade.
+	accomodations := accommodationService.SearchAccomodations(model.SearchAccomodationDTO{NumberOfGuests: 2,
+		StartDate: time.Date(2023, 6, 1, 0, 0, 0, 0, tokyo), EndDate: time.Date(2023, 6, 5, 0, 0, 0, 0, tokyo)}, context.Background())
+
+	assert.Len(t, accomodations, 1)
+	assert.Equal(t, date(2023, 6, 1), accomodations[0].StartDate)
+	assert.Equal(t, date(2023, 6, 5), accomodations[0].EndDate)
+	assert.Equal(t, rsd(24000), accomodations[0].TotalPrice)
+	assert.True(t, time.Date(2023, 6, 1, 14, 0, 0, 0, belgrade).Equal(reservedFrom))
+	assert.True(t, time.Date(2023, 6, 5, 10, 0, 0, 0, belgrade).Equal(reservedTo))
+}
+
+func TestSaveReservedTerm_UsesTimeZoneOfAccomodation(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	mockRepo := &MockRepo{
+		FindAccomodationByIdFn: func(id uint, ctx context.Context) (model.Accomodation, error) {
+			return model.Accomodation{Model: gorm.Model{ID: 1}, TimeZone: "America/New_York"}, nil
+		},
+		FindStayRulesForAccomodationFn: func(accomodationId uint, ctx context.Context) []model.StayRule {
+			return []model.StayRule{}
+		},
+		SaveReservedTermFn: func(reservedTerm model.ReservedTerm, ctx context.Context) model.ReservedTerm {
+			return reservedTerm
+		},
+	}
+
+	accommodationService := service.AccomodationService{
+		Repo: mockRepo,
+	}
+
+	reservedTerm, err := accommodationService.SaveReservedTerm(model.ReservedTerm{StartDate: time.Date(2023, 6, 1, 23, 0, 0, 0, time.UTC), EndDate: date(2023, 6, 3), AccomodationID: 1}, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 6, 1, 14, 0, 0, 0, newYork), reservedTerm.StartDate)
+	assert.Equal(t, time.Date(2023, 6, 3, 10, 0, 0, 0, newYork), reservedTerm.EndDate)
+}
+
+func TestValidateTurnover_UnknownTimeZone(t *testing.T) {
+	accommodationService := service.AccomodationService{
+		Repo: &MockRepo{},
+	}
+
+	err := accommodationService.ValidateTurnover(model.Accomodation{TimeZone: "Europe/Atlantis"})
+
+	assert.EqualError(t, err, "time zone Europe/Atlantis does not exist")
+}
+
+func TestAccomodationDateOf(t *testing.T) {
+	accomodation := model.Accomodation{}
+
+	assert.Equal(t, date(2023, 6, 2), accomodation.DateOf(time.Date(2023, 6, 1, 23, 30, 0, 0, time.UTC)))
+	assert.Equal(t, date(2023, 6, 1), accomodation.DateOf(time.Date(2023, 6, 1, 21, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/model"
+	"github.com/windbnb/accomodation-service/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryTracer keeps the spans ended during the test in memory.
+func useInMemoryTracer(t *testing.T) *tracetest.InMemoryExporter {
+	exporter, restore := tracer.InitInMemory()
+	t.Cleanup(restore)
+	return exporter
+}
+
+func endedSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %s was not ended", name)
+	return tracetest.SpanStub{}
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, keyValue := range span.Attributes {
+		if keyValue.Key == key {
+			return keyValue.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTransport_InjectsSpanContext(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	parent := tracer.StartSpanFromContext(context.Background(), "handler")
+	request, _ := http.NewRequestWithContext(tracer.ContextWithSpan(context.Background(), parent), http.MethodGet, server.URL, nil)
+
+	response, err := (&http.Client{Transport: &tracer.Transport{}}).Do(request)
+	assert.NoError(t, err)
+	response.Body.Close()
+
+	span := endedSpan(t, exporter, "HTTP GET")
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, int64(http.StatusAccepted), attributeValue(span, "http.status_code").AsInt64())
+	assert.Equal(t, "00-"+span.SpanContext.TraceID().String()+"-"+span.SpanContext.SpanID().String()+"-01", traceparent)
+	assert.Empty(t, request.Header.Get("traceparent"), "the request given is not modified")
+}
+
+func TestTransport_MarksServerErrors(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	parent := tracer.StartSpanFromContext(context.Background(), "handler")
+	request, _ := http.NewRequestWithContext(tracer.ContextWithSpan(context.Background(), parent), http.MethodGet, server.URL, nil)
+
+	response, err := (&http.Client{Transport: &tracer.Transport{}}).Do(request)
+	assert.NoError(t, err)
+	response.Body.Close()
+
+	assert.Equal(t, codes.Error, endedSpan(t, exporter, "HTTP GET").Status.Code)
+}
+
+func TestTransport_WithoutTrace(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	response, err := (&http.Client{Transport: &tracer.Transport{}}).Get(server.URL)
+	assert.NoError(t, err)
+	response.Body.Close()
+
+	assert.Empty(t, traceparent)
+	assert.Empty(t, exporter.GetSpans())
+}
+
+func TestUserServiceClient_PropagatesTrace(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(model.UserResponseDTO{Id: 4, Role: model.HOST})
+	}))
+	defer server.Close()
+	parent := tracer.StartSpanFromContext(context.Background(), "handler")
+
+	_, err := newUserServiceClient(t, server).AuthorizeHost("Bearer host", tracer.ContextWithSpan(context.Background(), parent))
+	assert.NoError(t, err)
+
+	clientSpan := endedSpan(t, exporter, "authorizeHostClient")
+	httpSpan := endedSpan(t, exporter, "HTTP POST")
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent.SpanID())
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), httpSpan.Parent.SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), httpSpan.SpanContext.TraceID())
+	assert.Contains(t, traceparent, parent.SpanContext().TraceID().String())
+}
+
+func TestStartSpanFromRequest_ContinuesTrace(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	request := httptest.NewRequest(http.MethodGet, "/api/accomodation/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	tracer.StartSpanFromRequest("findAccomodationByIdHandler", tracer.Global(), request).Finish()
+
+	span := endedSpan(t, exporter, "findAccomodationByIdHandler")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+}
+
+func TestLogError(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	span := tracer.StartSpanFromContext(context.Background(), "findAccomodationByIdService")
+
+	tracer.LogError(span, assert.AnError)
+	span.Finish()
+
+	ended := endedSpan(t, exporter, "findAccomodationByIdService")
+	assert.Equal(t, codes.Error, ended.Status.Code)
+	assert.Equal(t, assert.AnError.Error(), ended.Status.Description)
+	assert.Equal(t, "exception", ended.Events[0].Name)
+}
+
+func TestSampler(t *testing.T) {
+	sampler, err := tracer.Sampler("parentbased_traceidratio", "0.25")
+	assert.NoError(t, err)
+	assert.Equal(t, "ParentBased{root:TraceIDRatioBased{0.25},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}", sampler.Description())
+
+	sampler, err = tracer.Sampler("", "")
+	assert.NoError(t, err)
+	assert.Contains(t, sampler.Description(), "ParentBased{root:AlwaysOnSampler")
+
+	_, err = tracer.Sampler("traceidratio", "2")
+	assert.EqualError(t, err, `sampler ratio has to be between 0 and 1, got "2"`)
+	_, err = tracer.Sampler("const", "")
+	assert.EqualError(t, err, `unknown sampler "const"`)
+}
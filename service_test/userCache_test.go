@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/cache"
+	"github.com/windbnb/accomodation-service/client"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+// fakeUserService serves users by id and token, counting the calls made.
+type fakeUserService struct {
+	users  map[uint]model.UserResponseDTO
+	tokens map[string]uint
+	calls  int
+}
+
+func (service *fakeUserService) GetUserById(userId uint, ctx context.Context) (model.UserResponseDTO, error) {
+	service.calls++
+	user, found := service.users[userId]
+	if !found {
+		return model.UserResponseDTO{}, &client.StatusError{StatusCode: 404, Message: "user not found"}
+	}
+	return user, nil
+}
+
+func (service *fakeUserService) AuthorizeHost(tokenString string, ctx context.Context) (model.UserResponseDTO, error) {
+	service.calls++
+	userId, found := service.tokens[tokenString]
+	if !found {
+		return model.UserResponseDTO{}, &client.StatusError{StatusCode: 401, Message: "invalid token"}
+	}
+	return service.users[userId], nil
+}
+
+// failingStore fails every operation, like a Redis that can not be reached.
+type failingStore struct{}
+
+func (failingStore) Get(key string, ctx context.Context) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingStore) Set(key string, value []byte, ttl time.Duration, ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Delete(key string, ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (clock *testClock) Now() time.Time {
+	return clock.now
+}
+
+func newCachingUserService() (*client.CachingUserService, *fakeUserService, *testClock) {
+	userService := &fakeUserService{
+		users:  map[uint]model.UserResponseDTO{1: {Id: 1, Role: model.HOST}},
+		tokens: map[string]uint{"Bearer host": 1},
+	}
+	clock := &testClock{now: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	store := cache.NewMemoryStore(100)
+	store.Clock = clock.Now
+	cachingUserService := client.NewCachingUserService(userService, store, time.Minute)
+	cachingUserService.Clock = clock.Now
+	return cachingUserService, userService, clock
+}
+
+func TestMemoryStore_Expires(t *testing.T) {
+	clock := &testClock{now: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	store := cache.NewMemoryStore(10)
+	store.Clock = clock.Now
+
+	assert.NoError(t, store.Set("key", []byte("value"), time.Minute, context.Background()))
+	value, found, err := store.Get("key", context.Background())
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("value"), value)
+
+	clock.now = clock.now.Add(time.Minute)
+	_, found, _ = store.Get("key", context.Background())
+	assert.False(t, found)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryStore_DropsLeastRecentlyUsed(t *testing.T) {
+	store := cache.NewMemoryStore(2)
+	ctx := context.Background()
+
+	store.Set("a", []byte("a"), time.Minute, ctx)
+	store.Set("b", []byte("b"), time.Minute, ctx)
+	store.Get("a", ctx)
+	store.Set("c", []byte("c"), time.Minute, ctx)
+
+	_, foundA, _ := store.Get("a", ctx)
+	_, foundB, _ := store.Get("b", ctx)
+	_, foundC, _ := store.Get("c", ctx)
+	assert.True(t, foundA)
+	assert.False(t, foundB)
+	assert.True(t, foundC)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestCachingUserService_CachesUsers(t *testing.T) {
+	cachingUserService, userService, clock := newCachingUserService()
+
+	for i := 0; i < 3; i++ {
+		user, err := cachingUserService.GetUserById(1, context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), user.Id)
+	}
+	assert.Equal(t, 1, userService.calls)
+
+	clock.now = clock.now.Add(time.Minute)
+	cachingUserService.GetUserById(1, context.Background())
+	assert.Equal(t, 2, userService.calls)
+}
+
+func TestCachingUserService_CachesAuthorization(t *testing.T) {
+	cachingUserService, userService, _ := newCachingUserService()
+
+	for i := 0; i < 3; i++ {
+		user, err := cachingUserService.AuthorizeHost("Bearer host", context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, model.HOST, user.Role)
+	}
+
+	assert.Equal(t, 1, userService.calls)
+}
+
+func TestCachingUserService_DoesNotCacheErrors(t *testing.T) {
+	cachingUserService, userService, _ := newCachingUserService()
+
+	for i := 0; i < 2; i++ {
+		_, err := cachingUserService.AuthorizeHost("Bearer forged", context.Background())
+		assert.Equal(t, &client.StatusError{StatusCode: 401, Message: "invalid token"}, err)
+	}
+
+	assert.Equal(t, 2, userService.calls)
+}
+
+func TestCachingUserService_InvalidateUser(t *testing.T) {
+	cachingUserService, userService, clock := newCachingUserService()
+	cachingUserService.GetUserById(1, context.Background())
+	cachingUserService.AuthorizeHost("Bearer host", context.Background())
+
+	clock.now = clock.now.Add(time.Second)
+	assert.NoError(t, cachingUserService.InvalidateUser(1, context.Background()))
+	userService.users[1] = model.UserResponseDTO{Id: 1, Role: model.GUEST}
+	clock.now = clock.now.Add(time.Second)
+
+	user, err := cachingUserService.AuthorizeHost("Bearer host", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, model.GUEST, user.Role)
+	user, _ = cachingUserService.GetUserById(1, context.Background())
+	assert.Equal(t, model.GUEST, user.Role)
+	assert.Equal(t, 4, userService.calls)
+
+	cachingUserService.AuthorizeHost("Bearer host", context.Background())
+	assert.Equal(t, 4, userService.calls, "entries cached after the invalidation are used")
+}
+
+func TestCachingUserService_FailingStore(t *testing.T) {
+	userService := &fakeUserService{users: map[uint]model.UserResponseDTO{1: {Id: 1, Role: model.HOST}}}
+	cachingUserService := client.NewCachingUserService(userService, failingStore{}, time.Minute)
+
+	user, err := cachingUserService.GetUserById(1, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.Id)
+	assert.EqualError(t, cachingUserService.InvalidateUser(1, context.Background()), "connection refused")
+}
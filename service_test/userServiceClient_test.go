@@ -0,0 +1,189 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/accomodation-service/client"
+	"github.com/windbnb/accomodation-service/model"
+)
+
+// newUserServiceClient returns a client calling server that retries without
+// waiting, so tests stay fast.
+func newUserServiceClient(t *testing.T, server *httptest.Server) *client.UserServiceClient {
+	serverUrl, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	userClient := client.NewUserServiceClient(client.NewBalancer([]*url.URL{serverUrl}, client.RoundRobin))
+	userClient.RetryBackoff = 0
+	return userClient
+}
+
+func respondWith(statuses ...int) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[len(statuses)-1]
+		if requests < len(statuses) {
+			status = statuses[requests]
+		}
+		requests++
+
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			json.NewEncoder(w).Encode(model.UserResponseDTO{Id: 4, Role: model.HOST})
+			return
+		}
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: http.StatusText(status), StatusCode: status})
+	}))
+	return server, &requests
+}
+
+func TestUserServiceClient_GetUserById(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewEncoder(w).Encode(model.UserResponseDTO{Id: 4, Email: "host@windbnb.com", Role: model.HOST})
+	}))
+	defer server.Close()
+
+	user, err := newUserServiceClient(t, server).GetUserById(4, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/users/4", path)
+	assert.Equal(t, model.UserResponseDTO{Id: 4, Email: "host@windbnb.com", Role: model.HOST}, user)
+}
+
+func TestUserServiceClient_AuthorizeHostSendsToken(t *testing.T) {
+	var token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(model.UserResponseDTO{Id: 4, Role: model.HOST})
+	}))
+	defer server.Close()
+
+	user, err := newUserServiceClient(t, server).AuthorizeHost("Bearer host", context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer host", token)
+	assert.Equal(t, uint(4), user.Id)
+}
+
+func TestUserServiceClient_StatusError(t *testing.T) {
+	server, requests := respondWith(http.StatusUnauthorized)
+	defer server.Close()
+
+	_, err := newUserServiceClient(t, server).AuthorizeHost("Bearer forged", context.Background())
+
+	assert.Equal(t, &client.StatusError{StatusCode: http.StatusUnauthorized, Message: "Unauthorized"}, err)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestUserServiceClient_RetriesServerErrors(t *testing.T) {
+	server, requests := respondWith(http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	defer server.Close()
+
+	user, err := newUserServiceClient(t, server).GetUserById(4, context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), user.Id)
+	assert.Equal(t, 3, *requests)
+}
+
+func TestUserServiceClient_GivesUpAfterMaxRetries(t *testing.T) {
+	server, requests := respondWith(http.StatusInternalServerError)
+	defer server.Close()
+
+	_, err := newUserServiceClient(t, server).GetUserById(4, context.Background())
+
+	assert.Equal(t, &client.StatusError{StatusCode: http.StatusInternalServerError, Message: "Internal Server Error"}, err)
+	assert.Equal(t, 3, *requests)
+}
+
+func TestUserServiceClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	userClient := newUserServiceClient(t, server)
+	userClient.Timeout = 10 * time.Millisecond
+	userClient.MaxRetries = 0
+
+	_, err := userClient.GetUserById(4, context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUserServiceClient_StopsOnCancelledContext(t *testing.T) {
+	server, requests := respondWith(http.StatusServiceUnavailable)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newUserServiceClient(t, server).GetUserById(4, ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, *requests)
+}
+
+func TestUserServiceClient_CircuitBreakerOpens(t *testing.T) {
+	server, requests := respondWith(http.StatusServiceUnavailable)
+	defer server.Close()
+	userClient := newUserServiceClient(t, server)
+	userClient.MaxRetries = 0
+	userClient.Breaker = client.NewCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := userClient.GetUserById(4, context.Background())
+		assert.IsType(t, &client.StatusError{}, err)
+	}
+	_, err := userClient.GetUserById(4, context.Background())
+
+	assert.ErrorIs(t, err, client.ErrCircuitOpen)
+	assert.Equal(t, 2, *requests)
+}
+
+func TestUserServiceClient_ClientErrorsKeepCircuitClosed(t *testing.T) {
+	server, requests := respondWith(http.StatusNotFound)
+	defer server.Close()
+	userClient := newUserServiceClient(t, server)
+	userClient.Breaker = client.NewCircuitBreaker(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := userClient.GetUserById(4, context.Background())
+		assert.IsType(t, &client.StatusError{}, err)
+	}
+
+	assert.Equal(t, 3, *requests)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	breaker := client.NewCircuitBreaker(1, time.Minute)
+	breaker.Clock = func() time.Time { return now }
+
+	breaker.Failure()
+	assert.ErrorIs(t, breaker.Allow(), client.ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), client.ErrCircuitOpen, "only one trial call while half open")
+
+	breaker.Failure()
+	assert.ErrorIs(t, breaker.Allow(), client.ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.Allow(), "a trial that never reported back is replaced")
+	breaker.Success()
+	assert.NoError(t, breaker.Allow())
+	assert.NoError(t, breaker.Allow())
+}
@@ -4,79 +4,172 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
-	opentracing "github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
-	"github.com/opentracing/opentracing-go/log"
-	"github.com/uber/jaeger-client-go"
-	"github.com/uber/jaeger-client-go/config"
-	jaegerlog "github.com/uber/jaeger-client-go/log"
-	"github.com/uber/jaeger-lib/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Init returns an instance of Jaeger Tracer.
-func Init(service string) (opentracing.Tracer, io.Closer) {
-	cfg, err := config.FromEnv()
+const instrumentationName = "github.com/windbnb/accomodation-service"
+
+// Span is an OpenTelemetry span that can also be finished and logged to the
+// way handlers, services and repositories do.
+type Span struct {
+	trace.Span
+}
+
+func (span Span) Finish() {
+	span.End()
+}
+
+func (span Span) LogFields(fields ...attribute.KeyValue) {
+	span.AddEvent("log", trace.WithAttributes(fields...))
+}
+
+// Init installs a global tracer provider for the service and returns a
+// tracer for handlers along with a closer that flushes pending spans.
+//
+// Spans are exported over OTLP/HTTP to the collector configured through the
+// standard OTEL_EXPORTER_OTLP_* variables, or not at all when
+// OTEL_TRACES_EXPORTER is "none". OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG choose the sampler, and OTEL_RESOURCE_ATTRIBUTES
+// adds resource attributes.
+func Init(service string) (trace.Tracer, io.Closer) {
+	sampler, err := Sampler(os.Getenv("OTEL_TRACES_SAMPLER"), os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
 	if err != nil {
-		fmt.Println(err.Error())
-		return nil, nil
+		log.Fatal(err)
 	}
 
-	cfg.ServiceName = "accomodation-service"
-	cfg.Sampler.Type = jaeger.SamplerTypeConst
-	cfg.Sampler.Param = 1        // "const" sampler is a binary sampling strategy: 0=never sample, 1=always sample.
-	cfg.Reporter.LogSpans = true // Log the emitted spans to stdout.
-
-	jLogger := jaegerlog.StdLogger
-	jMetricsFactory := metrics.NullFactory
-	tracer, closer, err := cfg.NewTracer(
-		config.Logger(jLogger),
-		config.Metrics(jMetricsFactory),
-	)
+	serviceResource, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceNameKey.String(service)),
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithTelemetrySDK())
 	if err != nil {
-		panic(fmt.Sprintf("ERROR: cannot init Jaeger: %v\n", err))
+		log.Println(err)
 	}
-	return tracer, closer
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithSampler(sampler), sdktrace.WithResource(serviceResource)}
+	if os.Getenv("OTEL_TRACES_EXPORTER") != "none" {
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	install(provider)
+	return provider.Tracer(instrumentationName), providerCloser{provider}
+}
+
+// InitInMemory installs a tracer provider that samples every span and keeps
+// it in memory, for tests. The returned function restores the previous
+// provider.
+func InitInMemory() (*tracetest.InMemoryExporter, func()) {
+	previous := otel.GetTracerProvider()
+	exporter := tracetest.NewInMemoryExporter()
+	install(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSyncer(exporter)))
+	return exporter, func() { otel.SetTracerProvider(previous) }
+}
+
+func install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Sampler returns the sampler named like in OTEL_TRACES_SAMPLER. The ratio
+// samplers take the share of traces to sample from arg. Without a name every
+// trace is sampled unless the caller decided not to sample it.
+func Sampler(name string, arg string) (sdktrace.Sampler, error) {
+	ratio := 1.0
+	if arg != "" {
+		parsedRatio, err := strconv.ParseFloat(arg, 64)
+		if err != nil || parsedRatio < 0 || parsedRatio > 1 {
+			return nil, fmt.Errorf("sampler ratio has to be between 0 and 1, got %q", arg)
+		}
+		ratio = parsedRatio
+	}
+
+	switch name {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "", "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	}
+	return nil, fmt.Errorf("unknown sampler %q", name)
+}
+
+type providerCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (closer providerCloser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return closer.provider.Shutdown(ctx)
+}
+
+// Global returns the tracer of the installed tracer provider.
+func Global() trace.Tracer {
+	return otel.Tracer(instrumentationName)
 }
 
 // Inject injects the outbound HTTP request with the given span's context to ensure
 // correct propagation of span context throughout the trace.
-func Inject(span opentracing.Span, request *http.Request) error {
-	return span.Tracer().Inject(
-		span.Context(),
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(request.Header))
+func Inject(span Span, request *http.Request) {
+	otel.GetTextMapPropagator().Inject(
+		trace.ContextWithSpan(request.Context(), span.Span),
+		propagation.HeaderCarrier(request.Header))
 }
 
 // Extract extracts the inbound HTTP request to obtain the parent span's context to ensure
 // correct propagation of span context throughout the trace.
-func Extract(tracer opentracing.Tracer, r *http.Request) (opentracing.SpanContext, error) {
-	return tracer.Extract(
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(r.Header))
+func Extract(r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 }
 
 // StartSpanFromRequest extracts the parent span context from the inbound HTTP request
 // and starts a new child span if there is a parent span.
-func StartSpanFromRequest(spanName string, tracer opentracing.Tracer, r *http.Request) opentracing.Span {
-	spanCtx, _ := Extract(tracer, r)
-	return tracer.StartSpan(spanName, ext.RPCServerOption(spanCtx))
+func StartSpanFromRequest(spanName string, tracer trace.Tracer, r *http.Request) Span {
+	_, span := tracer.Start(Extract(r), spanName, trace.WithSpanKind(trace.SpanKindServer))
+	return Span{span}
 }
 
-func StartSpanFromContext(ctx context.Context, spanName string) opentracing.Span {
-	span, _ := opentracing.StartSpanFromContext(ctx, spanName)
-	return span
+func StartSpanFromContext(ctx context.Context, spanName string) Span {
+	_, span := Global().Start(ctx, spanName)
+	return Span{span}
 }
 
-func ContextWithSpan(ctx context.Context, span opentracing.Span) context.Context {
-	return opentracing.ContextWithSpan(ctx, span)
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return trace.ContextWithSpan(ctx, span.Span)
 }
 
-func LogString(key string, value string) log.Field {
-	return log.String(key, value)
+func LogString(key string, value string) attribute.KeyValue {
+	return attribute.String(key, value)
 }
 
-func LogError(span opentracing.Span, err error, fields ...log.Field) {
-	ext.LogError(span, err, fields...)
+func LogError(span Span, err error, fields ...attribute.KeyValue) {
+	span.RecordError(err, trace.WithAttributes(fields...))
+	span.SetStatus(codes.Error, err.Error())
 }
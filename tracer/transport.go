@@ -0,0 +1,62 @@
+package tracer
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport traces outbound HTTP requests. A request made with a context
+// carrying a span gets a child span for the call, and the span context is
+// injected into its headers so that the called service continues the trace.
+// Requests made outside of a trace are sent as they are.
+type Transport struct {
+	// Base sends the requests. http.DefaultTransport is used when it is nil.
+	Base http.RoundTripper
+}
+
+// WrapClient returns a copy of client whose requests are traced.
+func WrapClient(client *http.Client) *http.Client {
+	wrapped := *client
+	wrapped.Transport = &Transport{Base: client.Transport}
+	return &wrapped
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !trace.SpanContextFromContext(request.Context()).IsValid() {
+		return transport.base().RoundTrip(request)
+	}
+
+	ctx, otelSpan := Global().Start(request.Context(), "HTTP "+request.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(request.Method),
+			semconv.HTTPURLKey.String(request.URL.Redacted()),
+			semconv.NetPeerNameKey.String(request.URL.Hostname())))
+	span := Span{otelSpan}
+	defer span.Finish()
+
+	// RoundTrip must not modify the request it is given.
+	request = request.Clone(ctx)
+	Inject(span, request)
+
+	response, err := transport.base().RoundTrip(request)
+	if err != nil {
+		LogError(span, err)
+		return response, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, response.Status)
+	}
+	return response, nil
+}
+
+func (transport *Transport) base() http.RoundTripper {
+	if transport.Base == nil {
+		return http.DefaultTransport
+	}
+	return transport.Base
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"time"
 
@@ -13,8 +14,8 @@ import (
 
 var (
 	accomodations = []model.Accomodation{
-		{Name: "Vila Marija", Address: "Maksima Gorkog 17a, Novi Sad", HasWifi: true, HasKitchen: true, HasAirConditioning: true, HasFreeParking: false, MinimimGuests: 2, MaximumGuests: 5, UserId: 1, AcceptReservationType: model.MANUAL, PriceType: model.PER_GUEST},
-		{Name: "Lanterna", Address: "Ljubice Ravasi 32, Novi Sad", HasWifi: true, HasKitchen: false, HasAirConditioning: false, HasFreeParking: true, MinimimGuests: 4, MaximumGuests: 4, UserId: 1, AcceptReservationType: model.AUTOMATICALLY, PriceType: model.PER_GUEST},
+		{Name: "Vila Marija", Address: "Maksima Gorkog 17a, Novi Sad", HasWifi: true, HasKitchen: true, HasAirConditioning: true, HasFreeParking: false, MinimimGuests: 2, MaximumGuests: 5, UserId: 1, AcceptReservationType: model.MANUAL, PriceType: model.PER_GUEST, TimeZone: model.DefaultTimeZone},
+		{Name: "Lanterna", Address: "Ljubice Ravasi 32, Novi Sad", HasWifi: true, HasKitchen: false, HasAirConditioning: false, HasFreeParking: true, MinimimGuests: 4, MaximumGuests: 4, UserId: 1, AcceptReservationType: model.AUTOMATICALLY, PriceType: model.PER_GUEST, TimeZone: model.DefaultTimeZone},
 	}
 	accomodationImages = []model.AccomodationImage{
 		{ImageName: "373488187.jpg", AccomodationID: 1},
@@ -23,24 +24,23 @@ var (
 		{ImageName: "242225269.jpg", AccomodationID: 2},
 		{ImageName: "242218937.jpg", AccomodationID: 2},
 		{ImageName: "242216685.jpg", AccomodationID: 2},
-
 	}
 
 	prices = []model.Price{
-		{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
-			Value: 3000, PriceDuration: model.REGULAR, AccomodationID: 1, Active: true},
-		{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
-			Value: 5000, PriceDuration: model.HOLIDAY, AccomodationID: 1, Active: true},
-		{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
-			Value: 3500, PriceDuration: model.REGULAR, AccomodationID: 2, Active: true},
+		{StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Value: model.NewMoney(300000, model.DefaultCurrency), PriceDuration: model.REGULAR, AccomodationID: 1, Active: true},
+		{StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Value: model.NewMoney(500000, model.DefaultCurrency), PriceDuration: model.HOLIDAY, AccomodationID: 1, Active: true},
+		{StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Value: model.NewMoney(350000, model.DefaultCurrency), PriceDuration: model.REGULAR, AccomodationID: 2, Active: true},
 	}
 
-availableTerms = []model.AvailableTerm{
-		{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2023, 5, 1, 10, 0, 0, 0, time.Local),
+	availableTerms = []model.AvailableTerm{
+		{StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
 			AccomodationID: 1},
-		{StartDate: time.Date(2023, 5, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
+		{StartDate: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 			AccomodationID: 1},
-		{StartDate: time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local), EndDate: time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local),
+		{StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 			AccomodationID: 2},
 	}
 )
@@ -69,16 +69,17 @@ func ConnectToDatabase() *gorm.DB {
 		fmt.Println("Connection to DB successfull.")
 	}
 
-	db.DropTable("accomodations")
-	db.DropTable("accomodation_images")
-	db.DropTable("prices")
-	db.DropTable("reserved_terms")
-	db.DropTable("available_terms")
-	db.AutoMigrate(&model.Accomodation{})
-	db.AutoMigrate(&model.AccomodationImage{})
-	db.AutoMigrate(&model.Price{})
-	db.AutoMigrate(&model.ReservedTerm{})
-	db.AutoMigrate(&model.AvailableTerm{})
+	if err := MigrateDatabase(db); err != nil {
+		log.Fatal("migrating the database failed: ", err)
+	}
+
+	// Seed data is only added to an empty database, so that restarting the
+	// service keeps what was stored.
+	var accomodationCount int
+	db.Model(&model.Accomodation{}).Count(&accomodationCount)
+	if accomodationCount > 0 {
+		return db
+	}
 
 	for _, accomodation := range accomodations {
 		db.Create(&accomodation)
@@ -98,3 +99,84 @@ func ConnectToDatabase() *gorm.DB {
 
 	return db
 }
+
+// MigrateDatabase brings the schema up to date with the models. New columns
+// are added first so that migrateMoneyColumns can move the old amounts into
+// them.
+func MigrateDatabase(db *gorm.DB) error {
+	models := []interface{}{
+		&model.Accomodation{},
+		&model.AccomodationImage{},
+		&model.Price{},
+		&model.ReservedTerm{},
+		&model.AvailableTerm{},
+		&model.CalendarImport{},
+		&model.BlockedTerm{},
+		&model.AvailabilityRule{},
+		&model.StayRule{},
+		&model.DiscountRule{},
+		&model.Fee{},
+	}
+	for _, value := range models {
+		if err := db.AutoMigrate(value).Error; err != nil {
+			return err
+		}
+	}
+	return migrateMoneyColumns(db)
+}
+
+// migrateMoneyColumns converts the floating point amounts of older schemas
+// into minor units of DefaultCurrency, rounding half away from zero, and
+// drops the old columns. Percentage fees move to the percentage column.
+func migrateMoneyColumns(db *gorm.DB) error {
+	minorUnits := int64(math.Pow10(model.CurrencyExponent(model.DefaultCurrency)))
+
+	err := replaceColumn(db, &model.Price{}, "prices", "value", func(tx *gorm.DB) error {
+		return tx.Exec("UPDATE prices SET value_amount = ROUND(CAST(value AS NUMERIC) * ?), value_currency = ?",
+			minorUnits, model.DefaultCurrency).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	err = replaceColumn(db, &model.Accomodation{}, "accomodations", "extra_guest_fee", func(tx *gorm.DB) error {
+		return tx.Exec("UPDATE accomodations SET extra_guest_fee_amount = ROUND(CAST(extra_guest_fee AS NUMERIC) * ?), extra_guest_fee_currency = ?, currency = ?",
+			minorUnits, model.DefaultCurrency, model.DefaultCurrency).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return replaceColumn(db, &model.Fee{}, "fees", "amount", func(tx *gorm.DB) error {
+		err := tx.Exec("UPDATE fees SET percentage = amount, charge_amount = 0, charge_currency = ? WHERE basis = ?",
+			model.DefaultCurrency, model.PERCENTAGE).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE fees SET charge_amount = ROUND(CAST(amount AS NUMERIC) * ?), charge_currency = ? WHERE basis <> ?",
+			minorUnits, model.DefaultCurrency, model.PERCENTAGE).Error
+	})
+}
+
+// replaceColumn moves the values of an old column into new ones with convert
+// and drops the old column in one transaction, so that a failed conversion
+// leaves the old values where they were.
+func replaceColumn(db *gorm.DB, value interface{}, table string, column string, convert func(tx *gorm.DB) error) error {
+	if !db.Dialect().HasColumn(table, column) {
+		return nil
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := convert(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("converting %s.%s: %w", table, column, err)
+	}
+	if err := tx.Model(value).DropColumn(column).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("dropping %s.%s: %w", table, column, err)
+	}
+	return tx.Commit().Error
+}
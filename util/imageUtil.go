@@ -65,4 +65,3 @@ func SaveHeaderFileImages(files []*multipart.FileHeader) ([]string, error) {
 
 	return fileNames, nil
 }
-
@@ -2,18 +2,13 @@ package util
 
 import (
 	"net/http"
-	"net/url"
 	"strconv"
+	"strings"
 
-	roundrobin "github.com/hlts2/round-robin"
 	"github.com/windbnb/accomodation-service/model"
 )
 
-var BaseUserServicePathRoundRobin, _ = roundrobin.New(
-	&url.URL{Host: "http://nginx:8000"},
-)
-
-func ParseMultipartAccomodation(r *http.Request) model.Accomodation {
+func ParseMultipartAccomodation(r *http.Request) (model.Accomodation, error) {
 	name := r.MultipartForm.Value["name"][0]
 	address := r.MultipartForm.Value["address"][0]
 	hasWifi, _ := strconv.ParseBool(r.MultipartForm.Value["hasWifi"][0])
@@ -23,6 +18,16 @@ func ParseMultipartAccomodation(r *http.Request) model.Accomodation {
 	minimimGuests, _ := strconv.ParseUint(r.MultipartForm.Value["minimumGuests"][0], 10, 32)
 	maximumGuests, _ := strconv.ParseUint(r.MultipartForm.Value["maximumGuests"][0], 10, 32)
 	priceType := r.MultipartForm.Value["priceType"][0]
+	preparationDays, _ := strconv.ParseUint(optionalFormValue(r, "preparationDays"), 10, 32)
+	baseOccupancy, _ := strconv.ParseUint(optionalFormValue(r, "baseOccupancy"), 10, 32)
+	currency := optionalFormValue(r, "currency")
+	if currency == "" {
+		currency = model.DefaultCurrency
+	}
+	extraGuestFee, err := model.ParseMoney(defaultFormValue(r, "extraGuestFee", "0"), currency)
+	if err != nil {
+		return model.Accomodation{}, err
+	}
 
 	defaultAcceptReservationType := model.MANUAL
 
@@ -37,14 +42,37 @@ func ParseMultipartAccomodation(r *http.Request) model.Accomodation {
 		MaximumGuests:         uint(maximumGuests),
 		UserId:                0,
 		AcceptReservationType: defaultAcceptReservationType,
-		PriceType:             model.PriceType(priceType)}
+		PriceType:             model.PriceType(priceType),
+		CheckInTime:           optionalFormValue(r, "checkInTime"),
+		CheckOutTime:          optionalFormValue(r, "checkOutTime"),
+		CheckInDays:           optionalFormValue(r, "checkInDays"),
+		PreparationDays:       uint(preparationDays),
+		TimeZone:              optionalFormValue(r, "timeZone"),
+		BaseOccupancy:         uint(baseOccupancy),
+		ExtraGuestFee:         extraGuestFee,
+		Currency:              currency}, nil
+}
+
+func optionalFormValue(r *http.Request, key string) string {
+	values := r.MultipartForm.Value[key]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func defaultFormValue(r *http.Request, key string, defaultValue string) string {
+	if value := optionalFormValue(r, key); value != "" {
+		return value
+	}
+	return defaultValue
 }
 
 func FromCreatePriceDTOToPrice(price model.CreatePriceDTO) model.Price {
 
 	return model.Price{
-		StartDate:      price.StartDate,
-		EndDate:        price.EndDate,
+		StartDate:      model.CalendarDate(price.StartDate),
+		EndDate:        model.CalendarDate(price.EndDate),
 		Value:          price.Value,
 		PriceDuration:  price.PriceDuration,
 		AccomodationID: price.AccomodationID}
@@ -53,30 +81,76 @@ func FromCreatePriceDTOToPrice(price model.CreatePriceDTO) model.Price {
 func FromUpdatePriceDTOToPrice(price model.UpdatePriceDTO) model.Price {
 
 	return model.Price{
-		StartDate: price.StartDate,
-		EndDate:   price.EndDate,
+		StartDate: model.CalendarDate(price.StartDate),
+		EndDate:   model.CalendarDate(price.EndDate),
 		Value:     price.Value}
 }
 
 func FromCreateAvailableTermDTOToAvailableTerm(availableTerm model.CreateAvailableTermDTO) model.AvailableTerm {
 
 	return model.AvailableTerm{
-		StartDate:      availableTerm.StartDate,
-		EndDate:        availableTerm.EndDate,
+		StartDate:      model.CalendarDate(availableTerm.StartDate),
+		EndDate:        model.CalendarDate(availableTerm.EndDate),
 		AccomodationID: availableTerm.AccomodationID}
 }
 
 func FromUpdateAvailableTermDTOToAvailableTerm(availableTerm model.UpdateAvailableTermDTO) model.AvailableTerm {
 
 	return model.AvailableTerm{
-		StartDate: availableTerm.StartDate,
-		EndDate:   availableTerm.EndDate}
+		StartDate: model.CalendarDate(availableTerm.StartDate),
+		EndDate:   model.CalendarDate(availableTerm.EndDate)}
 }
 
 func FromCreateReservedTermDTOToReservedTerm(reservedTerm model.CreateReservedTermDTO) model.ReservedTerm {
 
 	return model.ReservedTerm{
-		StartDate:      reservedTerm.StartDate,
-		EndDate:        reservedTerm.EndDate,
+		StartDate:      model.CalendarDate(reservedTerm.StartDate),
+		EndDate:        model.CalendarDate(reservedTerm.EndDate),
 		AccomodationID: reservedTerm.AccomodationID}
 }
+
+func FromCreateAvailabilityRuleDTOToAvailabilityRule(availabilityRule model.CreateAvailabilityRuleDTO) model.AvailabilityRule {
+	var months []string
+	for _, month := range availabilityRule.Months {
+		months = append(months, strconv.Itoa(month))
+	}
+
+	return model.AvailabilityRule{
+		Weekdays:       strings.ToUpper(strings.Join(availabilityRule.Weekdays, ",")),
+		Months:         strings.Join(months, ","),
+		StartDate:      model.CalendarDate(availabilityRule.StartDate),
+		EndDate:        model.CalendarDate(availabilityRule.EndDate),
+		ExceptionDates: strings.Join(availabilityRule.ExceptionDates, ","),
+		AccomodationID: availabilityRule.AccomodationID}
+}
+
+func FromCreateStayRuleDTOToStayRule(stayRule model.CreateStayRuleDTO) model.StayRule {
+
+	return model.StayRule{
+		StartDate:      model.CalendarDate(stayRule.StartDate),
+		EndDate:        model.CalendarDate(stayRule.EndDate),
+		MinimumNights:  stayRule.MinimumNights,
+		MaximumNights:  stayRule.MaximumNights,
+		AccomodationID: stayRule.AccomodationID}
+}
+
+func FromCreateDiscountRuleDTOToDiscountRule(discountRule model.CreateDiscountRuleDTO) model.DiscountRule {
+
+	return model.DiscountRule{
+		Type:              discountRule.Type,
+		MinimumNights:     discountRule.MinimumNights,
+		DaysBeforeCheckIn: discountRule.DaysBeforeCheckIn,
+		Percentage:        discountRule.Percentage,
+		AccomodationID:    discountRule.AccomodationID}
+}
+
+func FromCreateFeeDTOToFee(fee model.CreateFeeDTO) model.Fee {
+
+	return model.Fee{
+		Name:           fee.Name,
+		Kind:           fee.Kind,
+		Basis:          fee.Basis,
+		Amount:         fee.Amount,
+		Percentage:     fee.Percentage,
+		AccomodationID: fee.AccomodationID}
+}